@@ -0,0 +1,123 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// aesCbcHmacAlgo implements AES_CBC_HMAC_SHA2 as defined in RFC 7518, Section
+// 5.2. The value is the size of the full key (MAC key + encryption key).
+type aesCbcHmacAlgo int
+
+func (a aesCbcHmacAlgo) String() string {
+	return fmt.Sprintf("A%dCBC-HS%d", a*4, a*8)
+}
+
+func (a aesCbcHmacAlgo) KeySize() int {
+	return int(a)
+}
+
+func (a aesCbcHmacAlgo) Hash() crypto.Hash {
+	switch a {
+	case 32:
+		return crypto.SHA256
+	case 48:
+		return crypto.SHA384
+	case 64:
+		return crypto.SHA512
+	}
+	return crypto.Hash(0)
+}
+
+// computeTag returns the authentication tag for the given values, which is
+// the first half of HMAC(MAC_KEY, A || IV || E || AL)
+func (a aesCbcHmacAlgo) computeTag(macKey, iv, ciphertext, aad []byte) []byte {
+	al := make([]byte, 8)
+	binary.BigEndian.PutUint64(al, uint64(len(aad))*8)
+
+	mac := hmac.New(a.Hash().New, macKey)
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(al)
+	return mac.Sum(nil)[:a/2]
+}
+
+func (a aesCbcHmacAlgo) Encrypt(rand io.Reader, cek, plaintext, aad []byte) ([]byte, []byte, []byte, error) {
+	if len(cek) != int(a) {
+		return nil, nil, nil, ErrInvalidEncryptKey
+	}
+	if !a.Hash().Available() {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrHashNotAvailable, a.Hash().String())
+	}
+	macKey, encKey := cek[:a/2], cek[a/2:]
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// PKCS #7 padding
+	pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+	buf := make([]byte, len(plaintext)+pad)
+	copy(buf, plaintext)
+	copy(buf[len(plaintext):], bytes.Repeat([]byte{byte(pad)}, pad))
+
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(buf, buf)
+
+	return iv, buf, a.computeTag(macKey, iv, buf, aad), nil
+}
+
+func (a aesCbcHmacAlgo) Decrypt(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+	if len(cek) != int(a) {
+		return nil, ErrInvalidDecryptKey
+	}
+	if !a.Hash().Available() {
+		return nil, fmt.Errorf("%w: %s", ErrHashNotAvailable, a.Hash().String())
+	}
+	macKey, encKey := cek[:a/2], cek[a/2:]
+
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrDecryptionFailed
+	}
+
+	// check tag before attempting to decrypt anything
+	if !hmac.Equal(tag, a.computeTag(macKey, iv, ciphertext, aad)) {
+		return nil, ErrDecryptionFailed
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(buf, ciphertext)
+
+	pad := int(buf[len(buf)-1])
+	if pad == 0 || pad > aes.BlockSize {
+		return nil, ErrDecryptionFailed
+	}
+	for _, v := range buf[len(buf)-pad:] {
+		if int(v) != pad {
+			return nil, ErrDecryptionFailed
+		}
+	}
+	return buf[:len(buf)-pad], nil
+}
+
+func (a aesCbcHmacAlgo) reg() EncAlgo {
+	RegisterEncAlgo(a)
+	return a
+}
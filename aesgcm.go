@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+)
+
+type aesGcmAlgo int
+
+func (a aesGcmAlgo) String() string {
+	return fmt.Sprintf("A%dGCM", a*8)
+}
+
+func (a aesGcmAlgo) KeySize() int {
+	return int(a)
+}
+
+func (a aesGcmAlgo) aead(cek []byte) (cipher.AEAD, error) {
+	if len(cek) != int(a) {
+		return nil, ErrInvalidEncryptKey
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (a aesGcmAlgo) Encrypt(rand io.Reader, cek, plaintext, aad []byte) ([]byte, []byte, []byte, error) {
+	aead, err := a.aead(cek)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// https://datatracker.ietf.org/doc/html/rfc7518#section-5.3 - 96 bits IV
+	iv := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return nil, nil, nil, err
+	}
+
+	res := aead.Seal(nil, iv, plaintext, aad)
+	ln := len(res) - aead.Overhead()
+	return iv, res[:ln], res[ln:], nil
+}
+
+func (a aesGcmAlgo) Decrypt(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+	aead, err := a.aead(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aead.NonceSize() || len(tag) != aead.Overhead() {
+		return nil, ErrDecryptionFailed
+	}
+
+	buf := make([]byte, 0, len(ciphertext)+len(tag))
+	buf = append(buf, ciphertext...)
+	buf = append(buf, tag...)
+
+	res, err := aead.Open(nil, iv, buf, aad)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return res, nil
+}
+
+func (a aesGcmAlgo) reg() EncAlgo {
+	RegisterEncAlgo(a)
+	return a
+}
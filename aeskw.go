@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+type aesKwAlgo int
+
+// aesKwIV is the default initial value defined in RFC 3394, Section 2.2.3.1
+var aesKwIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+func (a aesKwAlgo) String() string {
+	return fmt.Sprintf("A%dKW", a*8)
+}
+
+func (a aesKwAlgo) WrapKey(rand io.Reader, h Header, size int, pub crypto.PublicKey) ([]byte, []byte, error) {
+	kek, ok := symmetricKey(pub)
+	if !ok || len(kek) != int(a) {
+		return nil, nil, ErrInvalidEncryptKey
+	}
+
+	cek := make([]byte, size)
+	if _, err := io.ReadFull(rand, cek); err != nil {
+		return nil, nil, err
+	}
+
+	encKey, err := aesKeyWrap(kek, cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, encKey, nil
+}
+
+func (a aesKwAlgo) UnwrapKey(h Header, encryptedKey []byte, size int, priv crypto.PrivateKey) ([]byte, error) {
+	kek, ok := symmetricKey(priv)
+	if !ok || len(kek) != int(a) {
+		return nil, ErrInvalidDecryptKey
+	}
+
+	cek, err := aesKeyUnwrap(kek, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(cek) != size {
+		return nil, ErrDecryptionFailed
+	}
+	return cek, nil
+}
+
+func (a aesKwAlgo) reg() KeyAlgo {
+	RegisterKeyAlgo(a)
+	return a
+}
+
+// aesKeyWrap implements the AES key wrap algorithm as described in RFC 3394
+func aesKeyWrap(kek, cek []byte) ([]byte, error) {
+	if len(cek)%8 != 0 || len(cek) < 16 {
+		return nil, fmt.Errorf("%w: key to wrap must be a multiple of 64 bits", ErrInvalidEncryptKey)
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(cek) / 8
+	res := make([]byte, len(cek)+8)
+	copy(res, aesKwIV)
+	copy(res[8:], cek)
+
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf, res[:8])
+			copy(buf[8:], res[i*8:i*8+8])
+			block.Encrypt(buf, buf)
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(res[:8], binary.BigEndian.Uint64(buf[:8])^t)
+			copy(res[i*8:], buf[8:])
+		}
+	}
+	return res, nil
+}
+
+// aesKeyUnwrap reverses aesKeyWrap, and checks the integrity of the result
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, ErrDecryptionFailed
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	res := make([]byte, len(wrapped))
+	copy(res, wrapped)
+
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(res[:8])^t)
+			copy(buf[8:], res[i*8:i*8+8])
+			block.Decrypt(buf, buf)
+
+			copy(res[:8], buf[:8])
+			copy(res[i*8:], buf[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(res[:8], aesKwIV) != 1 {
+		return nil, ErrDecryptionFailed
+	}
+	return res[8:], nil
+}
@@ -50,7 +50,7 @@ var (
 	ES256  Algo = ecdsaAlgo("ES256").reg()
 	ES384  Algo = ecdsaAlgo("ES384").reg()
 	ES512  Algo = ecdsaAlgo("ES512").reg()
-	ES256K Algo = ecdsaAlgo("ES256K").reg()
+	ES256K Algo = ecdsaAlgo("ES256K").reg() // see Secp256k1 for signing restrictions
 
 	EdDSA Algo = ed25519Algo{}.reg()
 	None  Algo = noneAlgo{}.reg()
@@ -79,6 +79,9 @@ func parseAlgo(v string) Algo {
 
 // GetAlgoForSigner will guess the correct algorithm for a given [crypto.PrivateKey]
 func GetAlgoForSigner(s crypto.PrivateKey) (Algo, error) {
+	if _, ok := symmetricKey(s); ok {
+		return HS256, nil // symmetric key or oct JWK
+	}
 	if pub, ok := s.(interface{ Public() crypto.PublicKey }); ok {
 		switch pubkey := pub.Public().(type) {
 		case *ecdsa.PublicKey:
@@ -91,6 +94,8 @@ func GetAlgoForSigner(s crypto.PrivateKey) (Algo, error) {
 				return ES384, nil
 			case "P-521":
 				return ES512, nil
+			case "secp256k1":
+				return ES256K, nil
 			default:
 				return nil, fmt.Errorf("no known jwt algorithm for ECDSA curve %s", pubkey.Curve.Params().Name)
 			}
@@ -0,0 +1,212 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// NumericDate is a time value encoded in JSON as the number of seconds since
+// the epoch, as used by exp, iat and nbf claims (RFC 7519, Section 2). It can
+// be used in claims structs passed to Token.Claims and Token.SetClaims.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns a *NumericDate for the given time, truncated to the
+// second.
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{t.Truncate(time.Second)}
+}
+
+// MarshalJSON encodes the date as a number of seconds, with a fractional part
+// only if the time is not a whole second.
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	if d.Nanosecond() == 0 {
+		return strconv.AppendInt(nil, d.Unix(), 10), nil
+	}
+	v := float64(d.UnixNano()) / float64(time.Second)
+	return strconv.AppendFloat(nil, v, 'f', -1, 64), nil
+}
+
+// UnmarshalJSON decodes a number of seconds, which can include a fractional
+// part.
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	if i, err := n.Int64(); err == nil {
+		d.Time = time.Unix(i, 0)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	d.Time = floatToTime(f)
+	return nil
+}
+
+// floatToTime converts a number of seconds since the epoch with a fractional
+// part to a time value.
+func floatToTime(f float64) time.Time {
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+}
+
+// RegisteredClaims holds the registered claims defined in RFC 7519, Section
+// 4.1. It can be embedded in custom claims structs used with TypedToken.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  ClaimStrings `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// GetRegisteredClaims returns the registered claims, and allows access to them
+// from any struct embedding RegisteredClaims.
+func (c *RegisteredClaims) GetRegisteredClaims() *RegisteredClaims {
+	return c
+}
+
+// ClaimStrings is a list of strings that can be represented in JSON as either
+// a single string or an array, as allowed for the aud claim.
+type ClaimStrings []string
+
+// MarshalJSON encodes the value as a string if it contains a single element,
+// or as an array otherwise.
+func (s ClaimStrings) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+// UnmarshalJSON accepts either a string or an array of strings.
+func (s *ClaimStrings) UnmarshalJSON(b []byte) error {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch l := v.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		*s = ClaimStrings{l}
+		return nil
+	case []any:
+		res := make(ClaimStrings, 0, len(l))
+		for _, e := range l {
+			str, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("jwt: invalid type %T in string list", e)
+			}
+			res = append(res, str)
+		}
+		*s = res
+		return nil
+	default:
+		return fmt.Errorf("jwt: invalid type %T for string list", v)
+	}
+}
+
+// verifyClaims returns the claims checked by verification options. For tokens
+// that were parsed or signed, the signed payload is decoded again, so that
+// modifications made to Payload or to the claims of a TypedToken are not taken
+// into account. The result is kept for the duration of the call to Verify.
+func (tok *Token) verifyClaims() Payload {
+	if len(tok.values) < 2 || tok.detached {
+		return tok.Payload()
+	}
+	st := tok.state()
+	if st.claims == nil {
+		st.claims = Payload{}
+		if buf, err := tok.decodePayload(); err == nil {
+			var res Payload
+			dec := json.NewDecoder(bytes.NewReader(buf))
+			dec.UseNumber()
+			if dec.Decode(&res) == nil && res != nil {
+				st.claims = res
+			}
+		}
+	}
+	return st.claims
+}
+
+// claimDate returns the value of a NumericDate claim such as exp. ok will be
+// false if the claim is not set, and a zero time is returned if it failed to
+// parse.
+func (tok *Token) claimDate(key string) (res time.Time, ok bool) {
+	claims := tok.verifyClaims()
+	if !claims.Has(key) {
+		return time.Time{}, false
+	}
+	return claims.GetNumericDate(key), true
+}
+
+// claimString returns the value of a string claim such as iss. ok will be
+// false if the claim is not set.
+func (tok *Token) claimString(key string) (string, bool) {
+	claims := tok.verifyClaims()
+	if !claims.Has(key) {
+		return "", false
+	}
+	return claims.GetString(key), true
+}
+
+// claimStrings returns the value of a claim that can be either a string or an
+// array of strings, such as aud. ok will be false if the claim is not set.
+func (tok *Token) claimStrings(key string) ([]string, bool) {
+	claims := tok.verifyClaims()
+	if !claims.Has(key) {
+		return nil, false
+	}
+	return headerStrings(claims.Get(key)), true
+}
+
+// Claims decodes the token's payload into v, which is typically a pointer to
+// a struct with json tags. Values decoded into interface types will use
+// json.Number for numbers, similar to Payload.
+func (tok *Token) Claims(v any) error {
+	buf, err := tok.GetRawPayload()
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// SetClaims replaces the token's payload with the value of v, typically a
+// struct with json tags. v must encode to a JSON object.
+func (tok *Token) SetClaims(v any) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var payload Payload
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return err
+	}
+	if payload == nil {
+		return ErrNoPayload
+	}
+
+	tok.payload = payload
+	tok.detached = false
+	return nil
+}
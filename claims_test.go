@@ -0,0 +1,73 @@
+package jwt_test
+
+import (
+	_ "crypto/sha256"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+type testClaims struct {
+	Issuer    string           `json:"iss"`
+	ExpiresAt *jwt.NumericDate `json:"exp,omitempty"`
+	IssuedAt  jwt.NumericDate  `json:"iat"`
+	Admin     bool             `json:"admin"`
+	Count     json.Number      `json:"count"`
+	Extra     any              `json:"extra"`
+}
+
+func TestClaims(t *testing.T) {
+	priv := []byte("this is a hmac key")
+	now := time.Unix(1700000000, 0)
+
+	tok := jwt.New(jwt.HS256)
+	err := tok.SetClaims(&testClaims{
+		Issuer:    "myself",
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt:  jwt.NumericDate{Time: now.Add(500 * time.Millisecond)},
+		Admin:     true,
+		Count:     "12345678901234567890",
+		Extra:     42,
+	})
+	if err != nil {
+		t.Fatalf("failed to set claims: %s", err)
+	}
+	if tok.Payload().GetInt("exp") != 1700003600 {
+		t.Errorf("unexpected exp value in payload: %v", tok.Payload().Get("exp"))
+	}
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	var c testClaims
+	if err := tok2.Claims(&c); err != nil {
+		t.Fatalf("failed to read claims: %s", err)
+	}
+
+	if c.Issuer != "myself" || !c.Admin {
+		t.Errorf("unexpected claims: %+v", c)
+	}
+	if c.ExpiresAt == nil || !c.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("unexpected exp value: %v", c.ExpiresAt)
+	}
+	if !c.IssuedAt.Equal(now.Add(500 * time.Millisecond)) {
+		t.Errorf("unexpected iat value: %v", c.IssuedAt)
+	}
+	if c.Count != "12345678901234567890" {
+		t.Errorf("number precision was lost: %s", c.Count)
+	}
+	if _, ok := c.Extra.(json.Number); !ok {
+		t.Errorf("expected json.Number for extra, got %T", c.Extra)
+	}
+
+	if err := tok.SetClaims("not an object"); err == nil {
+		t.Errorf("setting non-object claims should fail")
+	}
+}
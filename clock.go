@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock provides the current time for time-based verifications such as
+// VerifyExpiresAt. It can be replaced in tests to freeze time.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc allows a function to be used as a Clock.
+type ClockFunc func() time.Time
+
+// Now returns the value of f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+var (
+	// DefaultClock is the clock used by time-based verifications when passed
+	// a zero time and no clock was set with VerifyClock.
+	DefaultClock Clock = ClockFunc(time.Now)
+
+	// DefaultLeeway is the allowed clock skew applied to exp, nbf and iat
+	// checks when none was set with VerifyLeeway.
+	DefaultLeeway time.Duration
+)
+
+// VerifyClock sets the clock used by the following time-based verifications
+// when they are passed a zero time, and must be passed before them.
+//
+// Example use: tok.Verify(VerifyClock(clk), VerifyTime(time.Time{}, false))
+func VerifyClock(clk Clock) VerifyOption {
+	return func(tok *Token) error {
+		tok.state().clock = clk
+		return nil
+	}
+}
+
+// VerifyLeeway sets the allowed clock skew for the following exp, nbf and iat
+// verifications, and must be passed before them. It overrides DefaultLeeway.
+func VerifyLeeway(leeway time.Duration) VerifyOption {
+	return func(tok *Token) error {
+		if leeway < 0 {
+			return fmt.Errorf("jwt: invalid negative leeway %s", leeway)
+		}
+		tok.state().leeway = &leeway
+		return nil
+	}
+}
+
+// now returns t, or the current time from the configured clock if t is zero
+func (tok *Token) now(t time.Time) time.Time {
+	if !t.IsZero() {
+		return t
+	}
+	if tok.vs != nil && tok.vs.clock != nil {
+		return tok.vs.clock.Now()
+	}
+	return DefaultClock.Now()
+}
+
+// leeway returns the allowed clock skew for the current verification
+func (tok *Token) leeway() time.Duration {
+	if tok.vs != nil && tok.vs.leeway != nil {
+		return *tok.vs.leeway
+	}
+	return DefaultLeeway
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// registeredHeaders lists the header parameter names defined by RFC 7515,
+// RFC 7516 and RFC 7518, which cannot appear in crit.
+var registeredHeaders = map[string]bool{
+	"alg": true, "jku": true, "jwk": true, "kid": true, "x5u": true,
+	"x5c": true, "x5t": true, "x5t#S256": true, "typ": true, "cty": true,
+	"crit": true, "enc": true, "zip": true, "epk": true, "apu": true,
+	"apv": true, "iv": true, "tag": true, "p2s": true, "p2c": true,
+}
+
+// builtinCritical lists the extensions this library understands
+var builtinCritical = map[string]bool{
+	"b64": true, // RFC 7797
+}
+
+// verifyState holds values for the duration of a call to Token.Verify
+type verifyState struct {
+	ctx      context.Context     // see Token.VerifyContext
+	crit     map[string]bool     // critical extensions understood by the caller
+	clock    Clock               // see VerifyClock
+	leeway   *time.Duration      // see VerifyLeeway
+	verified []*Signature        // signatures that were successfully verified
+	chain    []*x509.Certificate // see Token.VerifiedChain
+	claims   Payload             // see Token.verifyClaims
+}
+
+// state returns the current verification state of the token
+func (tok *Token) state() *verifyState {
+	if tok.vs == nil {
+		tok.vs = &verifyState{}
+	}
+	return tok.vs
+}
+
+// context returns the context passed to Token.VerifyContext
+func (tok *Token) context() context.Context {
+	if tok.vs == nil || tok.vs.ctx == nil {
+		return context.Background()
+	}
+	return tok.vs.ctx
+}
+
+// VerifyCritical declares that the caller understands and processes the
+// given header parameters, allowing them to be listed in the token's crit
+// header. Token.Verify always fails if crit contains a value that has not
+// been declared either this way, by the token's Algo (see below), or is not
+// supported by this library.
+//
+// A custom Algo can declare extensions it handles by implementing a
+// Critical() []string method.
+func VerifyCritical(names ...string) VerifyOption {
+	return func(tok *Token) error {
+		st := tok.state()
+		if st.crit == nil {
+			st.crit = make(map[string]bool)
+		}
+		for _, n := range names {
+			st.crit[n] = true
+		}
+		return nil
+	}
+}
+
+// checkCritical verifies that all the values in the header's crit parameter
+// are understood, as required by RFC 7515, Section 4.1.11. algos are checked
+// for a Critical() method.
+func checkCritical(h Header, understood map[string]bool, algos ...any) error {
+	v, ok := h["crit"]
+	if !ok {
+		return nil
+	}
+	list := headerStrings(v)
+	if _, isStr := v.(string); isStr || len(list) == 0 {
+		return fmt.Errorf("%w: crit must be a non-empty array", ErrInvalidToken)
+	}
+	if l, ok := v.([]any); ok && len(l) != len(list) {
+		return fmt.Errorf("%w: crit must only contain strings", ErrInvalidToken)
+	}
+
+	var algoCrit []string
+	for _, a := range algos {
+		if c, ok := a.(interface{ Critical() []string }); ok {
+			algoCrit = append(algoCrit, c.Critical()...)
+		}
+	}
+
+	for _, name := range list {
+		if registeredHeaders[name] {
+			return fmt.Errorf("%w: crit cannot contain registered header %s", ErrInvalidToken, name)
+		}
+		if !h.Has(name) {
+			return fmt.Errorf("%w: critical header %s is missing", ErrInvalidToken, name)
+		}
+		if builtinCritical[name] || understood[name] || stringsContain(algoCrit, name) {
+			continue
+		}
+		return fmt.Errorf("%w: %s", ErrUnsupportedCritical, name)
+	}
+	return nil
+}
+
+// checkCritical verifies the crit header of each of the token's signatures
+func (tok *Token) checkCritical() error {
+	var understood map[string]bool
+	if tok.vs != nil {
+		understood = tok.vs.crit
+	}
+
+	if len(tok.signatures) > 0 {
+		for _, sig := range tok.signatures {
+			if sig.unprotected.Has("crit") {
+				return fmt.Errorf("%w: crit must be integrity protected", ErrInvalidToken)
+			}
+			algo, _ := sig.GetAlgo()
+			if err := checkCritical(sig.header, understood, algo); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return checkCritical(tok.Header(), understood, tok.GetAlgo())
+}
+
+func stringsContain(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
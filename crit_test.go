@@ -0,0 +1,68 @@
+package jwt_test
+
+import (
+	_ "crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+// critAlgo is a custom algo handling the "custom" critical extension
+type critAlgo struct{ jwt.Algo }
+
+func (critAlgo) String() string {
+	return "HS256-crit"
+}
+
+func (critAlgo) Critical() []string {
+	return []string{"custom"}
+}
+
+func init() {
+	jwt.RegisterAlgo(critAlgo{jwt.HS256})
+}
+
+func TestCritical(t *testing.T) {
+	priv := []byte("this is a hmac key")
+
+	sign := func(alg jwt.Algo, crit any) *jwt.Token {
+		tok := jwt.New(alg)
+		tok.Header().Set("custom", "value")
+		tok.Header().Set("crit", crit)
+		res, err := tok.Sign(nil, priv)
+		if err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		tok, err = jwt.ParseString(res)
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		return tok
+	}
+
+	tok := sign(jwt.HS256, []string{"custom"})
+	if err := tok.Verify(jwt.VerifySignature(priv)); !errors.Is(err, jwt.ErrUnsupportedCritical) {
+		t.Errorf("expected ErrUnsupportedCritical, got %v", err)
+	}
+	if err := tok.Verify(); !errors.Is(err, jwt.ErrUnsupportedCritical) {
+		t.Errorf("expected ErrUnsupportedCritical without options, got %v", err)
+	}
+	if err := tok.Verify(jwt.VerifyCritical("custom"), jwt.VerifySignature(priv)); err != nil {
+		t.Errorf("failed to verify with VerifyCritical: %s", err)
+	}
+
+	// algo declares it handles the extension
+	tok = sign(critAlgo{jwt.HS256}, []string{"custom"})
+	if err := tok.Verify(jwt.VerifySignature(priv)); err != nil {
+		t.Errorf("failed to verify with algo handling crit: %s", err)
+	}
+
+	// invalid crit values
+	for _, crit := range []any{[]string{}, "custom", []string{"alg"}, []string{"missing"}, []any{1}} {
+		tok = sign(jwt.HS256, crit)
+		if err := tok.Verify(jwt.VerifyCritical("custom", "missing")); !errors.Is(err, jwt.ErrInvalidToken) {
+			t.Errorf("crit=%v: expected ErrInvalidToken, got %v", crit, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package jwt_test
+
+import (
+	_ "crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestUnencodedPayloadRFC7797(t *testing.T) {
+	// RFC 7797, Section 4.2
+	key, _ := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	tok, err := jwt.ParseString("eyJhbGciOiJIUzI1NiIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..A5dxf2s96_n5FLueVuW1Z_vh161FwXZC4YLPff6dmDY")
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if !tok.IsDetached() {
+		t.Errorf("token should be detached")
+	}
+	if err := tok.Verify(jwt.VerifySignature(key)); !errors.Is(err, jwt.ErrDetachedPayload) {
+		t.Errorf("expected ErrDetachedPayload, got %v", err)
+	}
+
+	tok.SetDetachedPayload([]byte("$.02"))
+	if err := tok.Verify(jwt.VerifySignature(key)); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+
+	// generate the same token
+	tok2 := jwt.New(jwt.HS256)
+	tok2.SetUnencodedPayload()
+	tok2.SetRawPayload([]byte("$.02"), "")
+	if _, err := tok2.Sign(nil, key); err == nil {
+		t.Errorf("signing payload containing a period in compact form should fail")
+	}
+	res, err := tok2.SignDetached(nil, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	sig := res[strings.LastIndexByte(res, '.'):]
+	if sig != ".A5dxf2s96_n5FLueVuW1Z_vh161FwXZC4YLPff6dmDY" {
+		t.Errorf("unexpected signature %s", res)
+	}
+}
+
+func TestDetachedPayload(t *testing.T) {
+	key := []byte("this is a hmac key")
+	body := []byte(`{"event":"ping"}`)
+
+	tok := jwt.New(jwt.HS256)
+	tok.SetRawPayload(body, "")
+	res, err := tok.SignDetached(nil, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if strings.Count(res, "..") != 1 {
+		t.Fatalf("token is not detached: %s", res)
+	}
+
+	tok2, err := jwt.ParseString(res)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if tok2.Payload() != nil {
+		t.Errorf("payload should not be available")
+	}
+	tok2.SetDetachedPayload(body)
+	if err := tok2.Verify(jwt.VerifySignature(key)); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+	if tok2.Payload().GetString("event") != "ping" {
+		t.Errorf("invalid value in body")
+	}
+
+	// modified body
+	tok2.SetDetachedPayload([]byte(`{"event":"pong"}`))
+	if err := tok2.Verify(jwt.VerifySignature(key)); err == nil {
+		t.Errorf("verification of modified body should fail")
+	}
+
+	// JSON serialization without payload
+	tok3, _ := jwt.ParseString(res)
+	buf, err := json.Marshal(tok3)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	if strings.Contains(string(buf), "payload") {
+		t.Errorf("detached payload included in JSON: %s", buf)
+	}
+	tok4, err := jwt.ParseJSON(buf)
+	if err != nil {
+		t.Fatalf("failed to parse JSON: %s", err)
+	}
+	tok4.SetDetachedPayload(body)
+	if err := tok4.Verify(jwt.VerifySignature(key)); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+}
+
+func TestUnencodedPayloadProtected(t *testing.T) {
+	key := []byte("this is a hmac key")
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	// b64 in an unprotected header must be rejected
+	tok := jwt.New(jwt.HS256)
+	tok.Payload().Set("iss", "myself")
+	if _, err := tok.Sign(nil, key); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	buf, err := json.Marshal(tok)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	var obj map[string]any
+	json.Unmarshal(buf, &obj)
+	obj["header"] = map[string]any{"b64": false}
+	buf, _ = json.Marshal(obj)
+	if _, err := jwt.ParseJSON(buf); !errors.Is(err, jwt.ErrInvalidToken) {
+		t.Errorf("expected unprotected b64 to be rejected, got %v", err)
+	}
+
+	// b64 must be listed in crit
+	tok = jwt.New(jwt.HS256)
+	tok.Header().Set("b64", false)
+	tok.SetRawPayload([]byte(`{"iss":"myself"}`), "")
+	res, err := tok.Sign(nil, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	tok2, err := jwt.ParseString(res)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifySignature(key)); !errors.Is(err, jwt.ErrInvalidToken) {
+		t.Errorf("expected b64 without crit to be rejected, got %v", err)
+	}
+
+	// all signatures must have the same b64 value
+	sig := enc("signature")
+	general := `{"payload":"e30","signatures":[` +
+		`{"protected":"` + enc(`{"alg":"HS256","b64":false,"crit":["b64"]}`) + `","signature":"` + sig + `"},` +
+		`{"protected":"` + enc(`{"alg":"HS256"}`) + `","signature":"` + sig + `"}]}`
+	if _, err := jwt.ParseJSON([]byte(general)); !errors.Is(err, jwt.ErrInvalidToken) {
+		t.Errorf("expected mixed b64 values to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package jwt
+
+import (
+	"crypto"
+	"io"
+)
+
+// dirAlgo implements direct encryption, where the shared symmetric key is
+// used as the content encryption key.
+type dirAlgo struct{}
+
+func (d dirAlgo) String() string {
+	return "dir"
+}
+
+func (d dirAlgo) WrapKey(rand io.Reader, h Header, size int, pub crypto.PublicKey) ([]byte, []byte, error) {
+	cek, ok := symmetricKey(pub)
+	if !ok || len(cek) != size {
+		return nil, nil, ErrInvalidEncryptKey
+	}
+	// encrypted key is empty with direct encryption
+	return cek, nil, nil
+}
+
+func (d dirAlgo) UnwrapKey(h Header, encryptedKey []byte, size int, priv crypto.PrivateKey) ([]byte, error) {
+	cek, ok := symmetricKey(priv)
+	if !ok || len(cek) != size {
+		return nil, ErrInvalidDecryptKey
+	}
+	if len(encryptedKey) != 0 {
+		return nil, ErrDecryptionFailed
+	}
+	return cek, nil
+}
+
+func (d dirAlgo) reg() KeyAlgo {
+	RegisterKeyAlgo(d)
+	return d
+}
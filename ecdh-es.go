@@ -0,0 +1,253 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ecdhEsAlgo implements ECDH-ES key agreement as defined in RFC 7518, Section
+// 4.6. A zero value means direct key agreement, otherwise the value is the
+// size of the AES key wrap key.
+type ecdhEsAlgo int
+
+func (e ecdhEsAlgo) String() string {
+	if e == 0 {
+		return "ECDH-ES"
+	}
+	return fmt.Sprintf("ECDH-ES+A%dKW", e*8)
+}
+
+func (e ecdhEsAlgo) WrapKey(rand io.Reader, h Header, size int, pub crypto.PublicKey) ([]byte, []byte, error) {
+	pk, err := ecdhPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eph, err := pk.Curve().GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	z, err := eph.ECDH(pk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	epk, err := ecdhExportPublicKey(eph.PublicKey())
+	if err != nil {
+		return nil, nil, err
+	}
+	h.Set("epk", epk)
+
+	if e == 0 {
+		// direct key agreement, derived key is the CEK
+		cek, err := e.deriveKey(h, z, size)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cek, nil, nil
+	}
+
+	kek, err := e.deriveKey(h, z, int(e))
+	if err != nil {
+		return nil, nil, err
+	}
+	return aesKwAlgo(e).WrapKey(rand, h, size, kek)
+}
+
+func (e ecdhEsAlgo) UnwrapKey(h Header, encryptedKey []byte, size int, priv crypto.PrivateKey) ([]byte, error) {
+	pk, err := ecdhPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+
+	epk, ok := h.GetValue("epk").(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: epk missing from header", ErrDecryptionFailed)
+	}
+	eph, err := ecdhImportPublicKey(epk)
+	if err != nil {
+		return nil, err
+	}
+	if eph.Curve() != pk.Curve() {
+		return nil, fmt.Errorf("%w: epk curve does not match key", ErrDecryptionFailed)
+	}
+
+	z, err := pk.ECDH(eph)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	if e == 0 {
+		if len(encryptedKey) != 0 {
+			return nil, ErrDecryptionFailed
+		}
+		return e.deriveKey(h, z, size)
+	}
+
+	kek, err := e.deriveKey(h, z, int(e))
+	if err != nil {
+		return nil, err
+	}
+	return aesKwAlgo(e).UnwrapKey(h, encryptedKey, size, kek)
+}
+
+// deriveKey implements the Concat KDF from NIST SP 800-56A using SHA-256 as
+// specified in RFC 7518, Section 4.6.2.
+func (e ecdhEsAlgo) deriveKey(h Header, z []byte, size int) ([]byte, error) {
+	algId := e.String()
+	if e == 0 {
+		algId = h.Get("enc")
+	}
+	apu, err := base64.RawURLEncoding.DecodeString(h.Get("apu"))
+	if err != nil {
+		return nil, fmt.Errorf("while reading apu: %w", err)
+	}
+	apv, err := base64.RawURLEncoding.DecodeString(h.Get("apv"))
+	if err != nil {
+		return nil, fmt.Errorf("while reading apv: %w", err)
+	}
+
+	var otherInfo []byte
+	for _, v := range [][]byte{[]byte(algId), apu, apv} {
+		otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(len(v)))
+		otherInfo = append(otherInfo, v...)
+	}
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(size)*8)
+
+	res := make([]byte, 0, size+sha256.Size)
+	for counter := uint32(1); len(res) < size; counter++ {
+		hash := sha256.New()
+		binary.Write(hash, binary.BigEndian, counter)
+		hash.Write(z)
+		hash.Write(otherInfo)
+		res = hash.Sum(res)
+	}
+	return res[:size], nil
+}
+
+func (e ecdhEsAlgo) reg() KeyAlgo {
+	RegisterKeyAlgo(e)
+	return e
+}
+
+// ecdhPublicKey returns the public key as a *ecdh.PublicKey
+func ecdhPublicKey(pub crypto.PublicKey) (*ecdh.PublicKey, error) {
+	if obj, ok := pub.(interface{ Public() crypto.PublicKey }); ok {
+		pub = obj.Public()
+	}
+	switch pk := pub.(type) {
+	case *ecdh.PublicKey:
+		return pk, nil
+	case *ecdsa.PublicKey:
+		return pk.ECDH()
+	default:
+		return nil, fmt.Errorf("%w: unknown type %T", ErrInvalidEncryptKey, pub)
+	}
+}
+
+// ecdhPrivateKey returns the private key as a *ecdh.PrivateKey
+func ecdhPrivateKey(priv crypto.PrivateKey) (*ecdh.PrivateKey, error) {
+	if jwk, ok := priv.(*JWK); ok {
+		priv = jwk.PrivateKey
+	}
+	switch pk := priv.(type) {
+	case *ecdh.PrivateKey:
+		return pk, nil
+	case *ecdsa.PrivateKey:
+		return pk.ECDH()
+	default:
+		return nil, fmt.Errorf("%w: unknown type %T", ErrInvalidDecryptKey, priv)
+	}
+}
+
+// ecdhExportPublicKey returns the public key in JWK format for use in epk
+func ecdhExportPublicKey(pub *ecdh.PublicKey) (map[string]any, error) {
+	buf := pub.Bytes()
+
+	switch pub.Curve() {
+	case ecdh.X25519():
+		return map[string]any{
+			"kty": "OKP",
+			"crv": "X25519",
+			"x":   base64.RawURLEncoding.EncodeToString(buf),
+		}, nil
+	case ecdh.P256(), ecdh.P384(), ecdh.P521():
+		// uncompressed point format: 0x04 || x || y
+		ln := (len(buf) - 1) / 2
+		return map[string]any{
+			"kty": "EC",
+			"crv": ecdhCurveName(pub.Curve()),
+			"x":   base64.RawURLEncoding.EncodeToString(buf[1 : ln+1]),
+			"y":   base64.RawURLEncoding.EncodeToString(buf[ln+1:]),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported curve", ErrInvalidEncryptKey)
+	}
+}
+
+// ecdhImportPublicKey parses a public key in JWK format as found in epk
+func ecdhImportPublicKey(v map[string]any) (*ecdh.PublicKey, error) {
+	crv, _ := v["crv"].(string)
+	x, _ := v["x"].(string)
+	xB, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, fmt.Errorf("while reading epk x: %w", err)
+	}
+
+	switch v["kty"] {
+	case "OKP":
+		if crv != "X25519" {
+			return nil, fmt.Errorf("unsupported epk curve %s", crv)
+		}
+		return ecdh.X25519().NewPublicKey(xB)
+	case "EC":
+		var curve ecdh.Curve
+		var ln int
+		switch crv {
+		case "P-256":
+			curve, ln = ecdh.P256(), 32
+		case "P-384":
+			curve, ln = ecdh.P384(), 48
+		case "P-521":
+			curve, ln = ecdh.P521(), 66
+		default:
+			return nil, fmt.Errorf("unsupported epk curve %s", crv)
+		}
+		y, _ := v["y"].(string)
+		yB, err := base64.RawURLEncoding.DecodeString(y)
+		if err != nil {
+			return nil, fmt.Errorf("while reading epk y: %w", err)
+		}
+		if len(xB) != ln || len(yB) != ln {
+			return nil, fmt.Errorf("%w: invalid epk coordinates length", ErrDecryptionFailed)
+		}
+		buf := make([]byte, 0, 1+ln*2)
+		buf = append(buf, 4)
+		buf = append(buf, xB...)
+		buf = append(buf, yB...)
+		// NewPublicKey will check the point is on the curve
+		return curve.NewPublicKey(buf)
+	default:
+		return nil, fmt.Errorf("unsupported epk key type %v", v["kty"])
+	}
+}
+
+func ecdhCurveName(c ecdh.Curve) string {
+	switch c {
+	case ecdh.P256():
+		return "P-256"
+	case ecdh.P384():
+		return "P-384"
+	case ecdh.P521():
+		return "P-521"
+	case ecdh.X25519():
+		return "X25519"
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package jwt
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"errors"
 	"fmt"
 	"io"
@@ -55,6 +56,28 @@ func (h ecdsaAlgo) Hash() crypto.Hash {
 	return crypto.Hash(0)
 }
 
+// GenerateKey returns a new ECDSA key on the curve of the algo, see
+// GenerateKey.
+func (h ecdsaAlgo) GenerateKey(rand io.Reader) (crypto.PrivateKey, error) {
+	var crv elliptic.Curve
+	switch h {
+	case "ES224":
+		crv = elliptic.P224()
+	case "ES256":
+		crv = elliptic.P256()
+	case "ES384":
+		crv = elliptic.P384()
+	case "ES512":
+		crv = elliptic.P521()
+	case "ES256K":
+		// see Secp256k1, the curve must not be used with private keys
+		return nil, fmt.Errorf("%w: key generation not supported for %s", ErrUnknownAlg, h)
+	default:
+		return nil, fmt.Errorf("%w: no curve for %s", ErrUnknownAlg, h)
+	}
+	return ecdsa.GenerateKey(crv, rand)
+}
+
 func (h ecdsaAlgo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([]byte, error) {
 	pk, ok := priv.(crypto.Signer)
 	if !ok {
@@ -64,7 +87,16 @@ func (h ecdsaAlgo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([]b
 	// ensure public key is a *ecdsa.PublicKey
 	switch h {
 	case ES256K:
-		// skip test since we want to allow secp256k1 key, maybe just check the curve?
+		// allow other secp256k1 implementations, but check the curve of ecdsa
+		// keys and refuse ours as it is not constant time
+		if pub, ok := pk.Public().(*ecdsa.PublicKey); ok {
+			if pub.Curve == Secp256k1() {
+				return nil, fmt.Errorf("%w: secp256k1 keys can only be used for verification", ErrInvalidSignKey)
+			}
+			if pub.Curve.Params().Name != "secp256k1" {
+				return nil, ErrInvalidSignKey
+			}
+		}
 	default:
 		if _, ok := pk.Public().(*ecdsa.PublicKey); !ok {
 			return nil, ErrInvalidSignKey
@@ -84,15 +116,19 @@ func (h ecdsaAlgo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([]b
 
 	// https://datatracker.ietf.org/doc/html/rfc7518#section-3.4
 	ln := h.digitLength()
+	var r, s []byte
 	if len(buf) == ln*2 {
 		// value is already good
-		return buf, nil
+		r, s = buf[:ln], buf[ln:]
+	} else {
+		// Sign() likely returned a ASN1 object, we need to decode it and return only R,S
+		r, s, err = parseEcdsaSignature(buf)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse ECDSA signature: %w", err)
+		}
 	}
-
-	// Sign() likely returned a ASN1 object, we need to decode it and return only R,S
-	r, s, err := parseEcdsaSignature(buf)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse ECDSA signature: %w", err)
+	if h == ES256K {
+		s = secp256k1LowS(s)
 	}
 
 	if len(r) > ln || len(s) > ln {
@@ -123,6 +159,23 @@ func (h ecdsaAlgo) Verify(buf, sign []byte, pub crypto.PublicKey) error {
 	if !ok {
 		return fmt.Errorf("%w: unknown type %T", ErrInvalidPublicKey, pub)
 	}
+	if h == ES256K {
+		if pk.Curve.Params().Name != "secp256k1" {
+			return fmt.Errorf("%w: ES256K requires a secp256k1 key", ErrInvalidPublicKey)
+		}
+		if pk.Curve != Secp256k1() {
+			// key from another secp256k1 implementation, use our curve for the
+			// arithmetic as the generic CurveParams one only handles a=-3
+			pk = &ecdsa.PublicKey{Curve: Secp256k1(), X: pk.X, Y: pk.Y}
+		}
+		if len(sign) != ln*2 {
+			return ErrInvalidSignatureLength
+		}
+		// only accept low S values to prevent signature malleability
+		if new(big.Int).SetBytes(sign[ln:]).Cmp(secp256k1HalfN()) > 0 {
+			return ErrInvalidSignature
+		}
+	}
 	if len(sign) != ln*2 {
 		if DeprecatedAllowEcdsaASN1Signatures {
 			// we're keeping this for now for backward compatibility - those signatures are not RFC7518 compliant
@@ -143,6 +196,21 @@ func (h ecdsaAlgo) Verify(buf, sign []byte, pub crypto.PublicKey) error {
 	return nil
 }
 
+// secp256k1HalfN returns N/2 for the secp256k1 curve
+func secp256k1HalfN() *big.Int {
+	return new(big.Int).Rsh(Secp256k1().Params().N, 1)
+}
+
+// secp256k1LowS returns s, or N-s if s is greater than N/2, as signatures with
+// either value are valid but only the low S form is accepted by Verify.
+func secp256k1LowS(s []byte) []byte {
+	v := new(big.Int).SetBytes(s)
+	if v.Cmp(secp256k1HalfN()) <= 0 {
+		return s
+	}
+	return v.Sub(Secp256k1().Params().N, v).Bytes()
+}
+
 func (h ecdsaAlgo) reg() Algo {
 	RegisterAlgo(h)
 	return h
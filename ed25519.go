@@ -20,6 +20,12 @@ func (h ed25519Algo) Hash() crypto.Hash {
 	return crypto.Hash(0)
 }
 
+// GenerateKey returns a new Ed25519 key, see GenerateKey.
+func (h ed25519Algo) GenerateKey(rand io.Reader) (crypto.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand)
+	return priv, err
+}
+
 func (h ed25519Algo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([]byte, error) {
 	pk, ok := priv.(crypto.Signer)
 	if !ok {
@@ -35,6 +41,10 @@ func (h ed25519Algo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([
 }
 
 func (h ed25519Algo) Verify(buf, sign []byte, pub crypto.PublicKey) error {
+	if obj, ok := pub.(interface{ Public() crypto.PublicKey }); ok {
+		pub = obj.Public()
+	}
+
 	pk, ok := pub.(ed25519.PublicKey)
 	if !ok {
 		return ErrInvalidSignature
@@ -1,6 +1,9 @@
 package jwt
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrInvalidToken           = errors.New("jwt: invalid token provided")
@@ -11,11 +14,42 @@ var (
 	ErrHashNotAvailable       = errors.New("jwt: hash method not available")
 	ErrNoHeader               = errors.New("jwt: header is not available (parsing failed?)")
 	ErrNoPayload              = errors.New("jwt: payload is not available (parsing failed?)")
+	ErrDetachedPayload        = errors.New("jwt: token payload is detached, use SetDetachedPayload")
 	ErrInvalidPublicKey       = errors.New("jwt: invalid public key provided")
 	ErrNoPrivateKey           = errors.New("jwt: private key is missing")
+	ErrUnsupportedKey         = errors.New("jwt: unsupported key type")
+	ErrKeyNotFound            = errors.New("jwt: no matching key found")
+	ErrKeySetFetch            = errors.New("jwt: failed to fetch key set")
+	ErrProviderDiscovery      = errors.New("jwt: OpenID provider discovery failed")
 	ErrAlgNotSet              = errors.New("jwt: alg has not been set in header")
 	ErrUnknownAlg             = errors.New("jwt: unrecognized alg value")
+	ErrAlgNotAllowed          = errors.New("jwt: algorithm not allowed")
+	ErrUnsupportedCritical    = errors.New("jwt: unsupported critical header parameter")
+	ErrEncNotSet              = errors.New("jwt: enc has not been set in header")
+	ErrUnknownEnc             = errors.New("jwt: unrecognized enc value")
+	ErrEncryptedToken         = errors.New("jwt: token is encrypted, use ParseJWE or ParseEncrypted")
+	ErrNotNestedToken         = errors.New("jwt: encrypted token does not contain a JWT")
+	ErrNotEncryptedToken      = errors.New("jwt: token is not encrypted")
+	ErrInvalidEncryptKey      = errors.New("jwt: invalid key provided for encryption")
+	ErrInvalidDecryptKey      = errors.New("jwt: invalid key provided for decryption")
+	ErrDecryptionFailed       = errors.New("jwt: token decryption failed")
 
-	ErrVerifyMissing = errors.New("jwt: a claim required for verification is missing")
-	ErrVerifyFailed  = errors.New("jwt: claim verification has failed")
+	ErrVerifyMissing  = errors.New("jwt: a claim required for verification is missing")
+	ErrVerifyFailed   = errors.New("jwt: claim verification has failed")
+	ErrVerifierConfig = errors.New("jwt: verifier is not properly configured")
 )
+
+// InvalidKeyError is returned when importing a JWK which values are invalid,
+// for example an EC point that is not on the curve.
+type InvalidKeyError struct {
+	Kty    string // key type, such as "EC"
+	Param  string // name of the invalid parameter, if known
+	Reason string
+}
+
+func (e *InvalidKeyError) Error() string {
+	if e.Param != "" {
+		return fmt.Sprintf("jwt: invalid %s key: %s: %s", e.Kty, e.Param, e.Reason)
+	}
+	return fmt.Sprintf("jwt: invalid %s key: %s", e.Kty, e.Reason)
+}
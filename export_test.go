@@ -0,0 +1,11 @@
+package jwt
+
+import "time"
+
+// The following methods expose internal helpers to the jwt_test package.
+
+func (tok *Token) ClaimString(key string) (string, bool) { return tok.claimString(key) }
+
+func (tok *Token) ClaimStrings(key string) ([]string, bool) { return tok.claimStrings(key) }
+
+func (tok *Token) ClaimDate(key string) (time.Time, bool) { return tok.claimDate(key) }
@@ -1,5 +1,5 @@
 module github.com/KarpelesLab/jwt
 
-go 1.19
+go 1.20
 
-require golang.org/x/crypto v0.19.0 // indirect
+require golang.org/x/crypto v0.19.0
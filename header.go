@@ -1,26 +1,81 @@
 package jwt
 
-import "fmt"
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
 
-// Header type holds values from the token's header for easy access
-type Header map[string]string
+// Header type holds values from the token's header for easy access. Values
+// can be of any JSON type, and typed accessors such as GetBool or GetStrings
+// are available.
+type Header map[string]any
 
 // Get will return the value of the requested key from the header, or an empty
-// string if the value is not found.
+// string if the value is not found or is not a string.
 func (h Header) Get(key string) string {
 	if h == nil {
 		return ""
 	}
-	if v, ok := h[key]; ok {
+	if v, ok := h[key].(string); ok {
 		return v
 	}
 	return ""
 }
 
+// GetValue returns the raw value of the requested key from the header, or nil
+// if not found.
+func (h Header) GetValue(key string) any {
+	if h == nil {
+		return nil
+	}
+	return h[key]
+}
+
+// GetBool returns the value of a boolean header such as b64, or false if the
+// value is not set or not a boolean.
+func (h Header) GetBool(key string) bool {
+	v, _ := h.GetValue(key).(bool)
+	return v
+}
+
+// GetInt returns the value of the requested key as an integer, with the same
+// conversion rules as Payload.GetInt.
+func (h Header) GetInt(key string) int64 {
+	return anyToInt(h.GetValue(key))
+}
+
+// GetStrings returns the value of a header containing a list of strings such
+// as crit or x5c. A single string value is returned as a list of one element.
+func (h Header) GetStrings(key string) []string {
+	return headerStrings(h.GetValue(key))
+}
+
+// GetJWK parses a header containing a JSON Web Key such as jwk or epk. nil
+// will be returned if the header is not set.
+func (h Header) GetJWK(key string) (*JWK, error) {
+	switch v := h.GetValue(key).(type) {
+	case nil:
+		return nil, nil
+	case *JWK:
+		return v, nil
+	case map[string]any:
+		res := &JWK{}
+		if err := res.ApplyValues(v); err != nil {
+			return nil, err
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T for JWK header %s", v, key)
+	}
+}
+
 // Set will update the key's value in the header and return nil. If there is
 // no header (because it failed to parse, for example), Set will return an
-// ErrNoHeader error. Calling Set on a nil Header will not panic.
-func (h Header) Set(key, value string) error {
+// ErrNoHeader error. Calling Set on a nil Header will not panic. value can be
+// a string or any value that can be marshalled to JSON.
+func (h Header) Set(key string, value any) error {
 	if h == nil {
 		return ErrNoHeader
 	}
@@ -57,3 +112,69 @@ func (h Header) GetAlgo() (Algo, error) {
 	}
 	return algObj, nil
 }
+
+// GetKeyAlgo returns the JWE key management algorithm set in the alg value of
+// the header. This will also work with custom algo as long as
+// RegisterKeyAlgo() was called.
+func (h Header) GetKeyAlgo() (KeyAlgo, error) {
+	alg := h.Get("alg")
+	if alg == "" {
+		return nil, ErrAlgNotSet
+	}
+	algObj := parseKeyAlgo(alg)
+	if algObj == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlg, alg)
+	}
+	return algObj, nil
+}
+
+// GetEncAlgo returns the JWE content encryption algorithm set in the enc
+// value of the header.
+func (h Header) GetEncAlgo() (EncAlgo, error) {
+	enc := h.Get("enc")
+	if enc == "" {
+		return nil, ErrEncNotSet
+	}
+	encObj := parseEncAlgo(enc)
+	if encObj == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEnc, enc)
+	}
+	return encObj, nil
+}
+
+// decodeHeader parses a base64url encoded JSON header. Numbers are decoded as
+// json.Number, similar to Payload.
+func decodeHeader(v string) (Header, error) {
+	str, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Header
+	dec := json.NewDecoder(bytes.NewReader(str))
+	dec.UseNumber()
+	if err = dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// headerStrings returns a list of strings from a header value, typically from
+// a JSON array.
+func headerStrings(v any) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []any:
+		res := make([]string, 0, len(l))
+		for _, e := range l {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	case string:
+		return []string{l}
+	}
+	return nil
+}
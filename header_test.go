@@ -0,0 +1,60 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	_ "crypto/sha256"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestHeaderValues(t *testing.T) {
+	priv := []byte("this is a hmac key")
+	tok := jwt.New(jwt.HS256)
+	tok.Header().Set("kid", "key1")
+	tok.Header().Set("b64", true)
+	tok.Header().Set("crit", []string{"exp", "b64"})
+	tok.Header().Set("x5c", []string{"MIIB", "MIIC"})
+	tok.Header().Set("jwk", (&jwt.JWK{PublicKey: Alice.Public()}).ExportRequiredPublicValues())
+	tok.Header().Set("exp", 1700000000)
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	hdr := tok2.Header()
+	if hdr == nil {
+		t.Fatalf("failed to parse header")
+	}
+	if err := tok2.Verify(jwt.VerifyCritical("exp"), jwt.VerifySignature(priv)); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+
+	if tok2.GetKeyId() != "key1" {
+		t.Errorf("bad kid value")
+	}
+	if hdr.Get("b64") != "" || !hdr.GetBool("b64") {
+		t.Errorf("bad b64 value")
+	}
+	if v := hdr.GetStrings("crit"); len(v) != 2 || v[0] != "exp" {
+		t.Errorf("bad crit value: %v", v)
+	}
+	if v := hdr.GetStrings("x5c"); len(v) != 2 || v[1] != "MIIC" {
+		t.Errorf("bad x5c value: %v", v)
+	}
+	if v := hdr.GetInt("exp"); v != 1700000000 {
+		t.Errorf("bad exp value: %d", v)
+	}
+
+	k, err := hdr.GetJWK("jwk")
+	if err != nil {
+		t.Fatalf("failed to read jwk: %s", err)
+	}
+	if !Alice.Public().(*ecdsa.PublicKey).Equal(k.PublicKey) {
+		t.Errorf("jwk header does not match")
+	}
+}
@@ -28,8 +28,18 @@ func (h hmacAlgo) Hash() crypto.Hash {
 	return crypto.Hash(h)
 }
 
+// GenerateKey returns a new random secret of the size of the hash, see
+// GenerateKey.
+func (h hmacAlgo) GenerateKey(rand io.Reader) (crypto.PrivateKey, error) {
+	res := make([]byte, h.Hash().Size())
+	if _, err := io.ReadFull(rand, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (h hmacAlgo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([]byte, error) {
-	pk, ok := priv.([]byte)
+	pk, ok := symmetricKey(priv)
 	if !ok {
 		return nil, ErrInvalidSignKey
 	}
@@ -43,7 +53,7 @@ func (h hmacAlgo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([]by
 }
 
 func (h hmacAlgo) Verify(buf, sign []byte, pub crypto.PublicKey) error {
-	pk, ok := pub.([]byte)
+	pk, ok := symmetricKey(pub)
 	if !ok {
 		return ErrInvalidSignature
 	}
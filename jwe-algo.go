@@ -0,0 +1,105 @@
+package jwt
+
+import (
+	"crypto"
+	"io"
+)
+
+// KeyAlgo is a JWE key management algorithm, used to determine the content
+// encryption key (CEK) of an encrypted token. Typical values include
+// RSAOAEP256 and A128KW. As with Algo, you can implement this interface to
+// add support for custom types, and call RegisterKeyAlgo() so it can be
+// recognized.
+type KeyAlgo interface {
+	// String should return the name of the algo, for example "RSA-OAEP"
+	String() string
+
+	// WrapKey returns the content encryption key to use, of size bytes,
+	// as well as its encrypted value to be included in the token. The
+	// header may be updated if the algorithm requires extra parameters.
+	WrapKey(rand io.Reader, h Header, size int, pub crypto.PublicKey) (cek, encryptedKey []byte, err error)
+
+	// UnwrapKey must recover the content encryption key from the encrypted
+	// value, or return an error if the key is not of the appropriate type
+	// or the value cannot be decrypted.
+	UnwrapKey(h Header, encryptedKey []byte, size int, priv crypto.PrivateKey) ([]byte, error)
+}
+
+// EncAlgo is a JWE content encryption algorithm, such as A128GCM. Remember to
+// call RegisterEncAlgo() for custom implementations.
+type EncAlgo interface {
+	// String should return the name of the algo, for example "A128GCM"
+	String() string
+
+	// KeySize returns the length in bytes of the content encryption key
+	KeySize() int
+
+	// Encrypt encrypts and authenticates plaintext as well as the
+	// additional authenticated data, and returns the generated
+	// initialization vector, the ciphertext and the authentication tag.
+	Encrypt(rand io.Reader, cek, plaintext, aad []byte) (iv, ciphertext, tag []byte, err error)
+
+	// Decrypt must check the authentication tag and return the plaintext,
+	// or an error if the values could not be authenticated.
+	Decrypt(cek, iv, ciphertext, tag, aad []byte) ([]byte, error)
+}
+
+var (
+	// key management & content encryption algos are found in RFC7518:
+	// https://datatracker.ietf.org/doc/html/rfc7518#section-4
+
+	RSAOAEP    KeyAlgo = rsaOaepAlgo(crypto.SHA1).reg()
+	RSAOAEP256 KeyAlgo = rsaOaepAlgo(crypto.SHA256).reg()
+
+	A128KW KeyAlgo = aesKwAlgo(16).reg()
+	A192KW KeyAlgo = aesKwAlgo(24).reg()
+	A256KW KeyAlgo = aesKwAlgo(32).reg()
+
+	Direct KeyAlgo = dirAlgo{}.reg()
+
+	ECDHES       KeyAlgo = ecdhEsAlgo(0).reg()
+	ECDHESA128KW KeyAlgo = ecdhEsAlgo(16).reg()
+	ECDHESA192KW KeyAlgo = ecdhEsAlgo(24).reg()
+	ECDHESA256KW KeyAlgo = ecdhEsAlgo(32).reg()
+
+	PBES2HS256A128KW KeyAlgo = pbes2Algo(16).reg()
+	PBES2HS384A192KW KeyAlgo = pbes2Algo(24).reg()
+	PBES2HS512A256KW KeyAlgo = pbes2Algo(32).reg()
+
+	A128GCM EncAlgo = aesGcmAlgo(16).reg()
+	A192GCM EncAlgo = aesGcmAlgo(24).reg()
+	A256GCM EncAlgo = aesGcmAlgo(32).reg()
+
+	A128CBCHS256 EncAlgo = aesCbcHmacAlgo(32).reg()
+	A192CBCHS384 EncAlgo = aesCbcHmacAlgo(48).reg()
+	A256CBCHS512 EncAlgo = aesCbcHmacAlgo(64).reg()
+
+	keyAlgoMap = make(map[string]KeyAlgo)
+	encAlgoMap = make(map[string]EncAlgo)
+)
+
+// RegisterKeyAlgo allows registration of custom key management algorithms.
+// Like RegisterAlgo, this is expected to be called during init.
+func RegisterKeyAlgo(obj KeyAlgo) {
+	keyAlgoMap[obj.String()] = obj
+}
+
+// RegisterEncAlgo allows registration of custom content encryption
+// algorithms. Like RegisterAlgo, this is expected to be called during init.
+func RegisterEncAlgo(obj EncAlgo) {
+	encAlgoMap[obj.String()] = obj
+}
+
+func parseKeyAlgo(v string) KeyAlgo {
+	if a, ok := keyAlgoMap[v]; ok {
+		return a
+	}
+	return nil
+}
+
+func parseEncAlgo(v string) EncAlgo {
+	if a, ok := encAlgoMap[v]; ok {
+		return a
+	}
+	return nil
+}
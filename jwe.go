@@ -0,0 +1,267 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JWE represents an encrypted token using the compact serialization defined
+// in RFC 7516, made of five base64url encoded parts.
+type JWE struct {
+	header Header // parsed if needed
+	values []string
+	value  string
+}
+
+// NewJWE returns a new encrypted token with the "alg" and "enc" values of the
+// header set, which can be used to encrypt data using the Encrypt method.
+func NewJWE(alg KeyAlgo, enc EncAlgo) *JWE {
+	return &JWE{
+		header: Header{"alg": alg.String(), "enc": enc.String()},
+	}
+}
+
+// ParseJWE will generate a JWE object from an encoded string. The token will
+// not be decrypted until Decrypt is called.
+func ParseJWE(value string) (*JWE, error) {
+	split := strings.Split(value, ".")
+
+	if len(split) != 5 {
+		return nil, ErrInvalidToken
+	}
+
+	return &JWE{
+		value:  value,
+		values: split,
+	}, nil
+}
+
+// Header returns the decoded protected header of the token.
+func (tok *JWE) Header() Header {
+	if tok.header != nil {
+		return tok.header
+	}
+
+	tok.header, _ = decodeHeader(tok.values[0])
+	return tok.header
+}
+
+// GetKeyId is a short hand for Header().Get("kid").
+func (tok *JWE) GetKeyId() string {
+	return tok.Header().Get("kid")
+}
+
+// GetContentType returns the value of "cty" in the token's header, with
+// the same rules as Token.GetContentType, except an empty string is returned
+// if not set.
+func (tok *JWE) GetContentType() string {
+	cty := tok.Header().Get("cty")
+	if cty == "" {
+		return ""
+	}
+	if strings.IndexByte(cty, '/') == -1 {
+		return "application/" + cty
+	}
+	return cty
+}
+
+// String returns the encoded token, if it was parsed or encrypted.
+func (tok *JWE) String() string {
+	return tok.value
+}
+
+// Encrypt will encrypt the plaintext for the given key, and return the
+// resulting token. The type of key depends on the key management algorithm,
+// for example *rsa.PublicKey for RSAOAEP or a []byte for A128KW. If rand is
+// nil, crypto/rand.Reader will be used.
+func (tok *JWE) Encrypt(rnd io.Reader, key crypto.PublicKey, plaintext []byte) (string, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	if tok.header == nil {
+		tok.header = make(Header)
+	}
+
+	keyAlgo, err := tok.header.GetKeyAlgo()
+	if err != nil {
+		return "", err
+	}
+	encAlgo, err := tok.header.GetEncAlgo()
+	if err != nil {
+		return "", err
+	}
+
+	cek, encKey, err := keyAlgo.WrapKey(rnd, tok.header, encAlgo.KeySize(), key)
+	if err != nil {
+		return "", err
+	}
+
+	// header might have been updated by WrapKey, encode it now
+	jsonVal, err := json.Marshal(tok.header)
+	if err != nil {
+		return "", err
+	}
+	hdr := base64.RawURLEncoding.EncodeToString(jsonVal)
+
+	iv, ciphertext, tag, err := encAlgo.Encrypt(rnd, cek, plaintext, []byte(hdr))
+	if err != nil {
+		return "", err
+	}
+
+	tok.values = []string{
+		hdr,
+		base64.RawURLEncoding.EncodeToString(encKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}
+	tok.value = strings.Join(tok.values, ".")
+
+	return tok.value, nil
+}
+
+// DecryptOption configures how a JWE is decrypted, see DecryptAlgo.
+type DecryptOption func(*decryptConfig) error
+
+// decryptConfig holds the values set by DecryptOption for a call to Decrypt
+type decryptConfig struct {
+	algs          []KeyAlgo
+	encs          []EncAlgo
+	maxIterations int // see DecryptMaxIterations
+}
+
+// DecryptAlgo sets the key management algorithms accepted when decrypting a
+// token. It must always be passed, as the token's alg value is otherwise
+// under the control of whoever created it (RFC 8725, Section 3.1).
+//
+// Example use: DecryptAlgo(jwt.RSAOAEP256)
+func DecryptAlgo(algs ...KeyAlgo) DecryptOption {
+	return func(cfg *decryptConfig) error {
+		cfg.algs = append(cfg.algs, algs...)
+		return nil
+	}
+}
+
+// DecryptEnc limits the content encryption algorithms accepted when
+// decrypting a token. If not used, all registered algorithms are accepted.
+func DecryptEnc(encs ...EncAlgo) DecryptOption {
+	return func(cfg *decryptConfig) error {
+		cfg.encs = append(cfg.encs, encs...)
+		return nil
+	}
+}
+
+// DecryptMaxIterations sets the maximum PBKDF2 iteration count (p2c) accepted
+// when decrypting a token using a PBES2 algorithm. The default is 100000.
+// As p2c is chosen by whoever created the token, this bounds the amount of
+// work done for each decryption.
+func DecryptMaxIterations(n int) DecryptOption {
+	return func(cfg *decryptConfig) error {
+		if n < pbes2MinIterations {
+			return fmt.Errorf("jwt: maximum iterations cannot be below %d", pbes2MinIterations)
+		}
+		cfg.maxIterations = n
+		return nil
+	}
+}
+
+// newDecryptConfig applies the passed options and checks the key management
+// algorithms have been set.
+func newDecryptConfig(opts []DecryptOption) (*decryptConfig, error) {
+	cfg := &decryptConfig{maxIterations: pbes2DefaultMaxIterations}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.algs) == 0 {
+		return nil, fmt.Errorf("%w: no key management algorithm allowed, use DecryptAlgo", ErrAlgNotAllowed)
+	}
+	return cfg, nil
+}
+
+// checkAlgos ensures the token's algorithms are allowed by the configuration
+func (cfg *decryptConfig) checkAlgos(keyAlgo KeyAlgo, encAlgo EncAlgo) error {
+	found := false
+	for _, a := range cfg.algs {
+		if a.String() == keyAlgo.String() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: unexpected key management algorithm %s", ErrAlgNotAllowed, keyAlgo)
+	}
+	if len(cfg.encs) == 0 {
+		return nil
+	}
+	for _, e := range cfg.encs {
+		if e.String() == encAlgo.String() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unexpected content encryption algorithm %s", ErrAlgNotAllowed, encAlgo)
+}
+
+// Decrypt will decrypt the token using the given key and return the
+// plaintext. The key is typically a crypto.Decrypter such as *rsa.PrivateKey
+// or *JWK, or a []byte for symmetric algorithms. The accepted algorithms
+// must be set with DecryptAlgo.
+//
+// Example use: tok.Decrypt(priv, jwt.DecryptAlgo(jwt.RSAOAEP256))
+func (tok *JWE) Decrypt(key crypto.PrivateKey, opts ...DecryptOption) ([]byte, error) {
+	if len(tok.values) != 5 {
+		return nil, ErrInvalidToken
+	}
+	cfg, err := newDecryptConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	hdr := tok.Header()
+	if hdr == nil {
+		return nil, ErrNoHeader
+	}
+	if zip := hdr.Get("zip"); zip != "" {
+		return nil, fmt.Errorf("%w: unsupported zip value %s", ErrInvalidToken, zip)
+	}
+
+	keyAlgo, err := hdr.GetKeyAlgo()
+	if err != nil {
+		return nil, err
+	}
+	encAlgo, err := hdr.GetEncAlgo()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkAlgos(keyAlgo, encAlgo); err != nil {
+		return nil, err
+	}
+	if err := checkCritical(hdr, nil, keyAlgo, encAlgo); err != nil {
+		return nil, err
+	}
+
+	bin := make([][]byte, 4)
+	for n := range bin {
+		bin[n], err = base64.RawURLEncoding.DecodeString(tok.values[n+1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+		}
+	}
+
+	var cek []byte
+	if p, ok := keyAlgo.(pbes2Algo); ok {
+		cek, err = p.unwrapKey(hdr, bin[0], encAlgo.KeySize(), key, cfg.maxIterations)
+	} else {
+		cek, err = keyAlgo.UnwrapKey(hdr, bin[0], encAlgo.KeySize(), key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return encAlgo.Decrypt(cek, bin[1], bin[2], bin[3], []byte(tok.values[0]))
+}
@@ -0,0 +1,365 @@
+package jwt_test
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestJWEDecryptRFC7516(t *testing.T) {
+	// RFC 7516, Appendix A.3
+	key, _ := base64.RawURLEncoding.DecodeString("GawgguFyGrWKav7AX4VKUg")
+	tok, err := jwt.ParseJWE("eyJhbGciOiJBMTI4S1ciLCJlbmMiOiJBMTI4Q0JDLUhTMjU2In0." +
+		"6KB707dM9YTIgHtLvtgWQ8mKwboJW3of9locizkDTHzBC2IlrT1oOQ." +
+		"AxY8DCtDaGlsbGljb3RoZQ." +
+		"KDlTtXchhZTGufMYmOYGS4HffxPSUrfmqCHXaI9wOGY." +
+		"U0m_YmjN04DJvceFICbCVQ")
+	if err != nil {
+		t.Fatalf("failed to parse token: %s", err)
+	}
+
+	res, err := tok.Decrypt(key, jwt.DecryptAlgo(jwt.A128KW))
+	if err != nil {
+		t.Fatalf("failed to decrypt token: %s", err)
+	}
+	if string(res) != "Live long and prosper." {
+		t.Errorf("unexpected plaintext: %q", res)
+	}
+
+	// altering the key must fail
+	key[0] ^= 1
+	if _, err = tok.Decrypt(key, jwt.DecryptAlgo(jwt.A128KW)); !errors.Is(err, jwt.ErrDecryptionFailed) {
+		t.Errorf("expected decryption failure, got %v", err)
+	}
+}
+
+func TestJWERoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	rsaJwk := &jwt.JWK{PrivateKey: rsaKey}
+
+	tests := []struct {
+		alg  jwt.KeyAlgo
+		pub  any
+		priv any
+	}{
+		{jwt.RSAOAEP, &rsaKey.PublicKey, rsaKey},
+		{jwt.RSAOAEP256, rsaJwk, rsaJwk},
+		{jwt.A128KW, make([]byte, 16), make([]byte, 16)},
+		{jwt.A256KW, make([]byte, 32), make([]byte, 32)},
+	}
+	encs := []jwt.EncAlgo{jwt.A128GCM, jwt.A256GCM, jwt.A128CBCHS256, jwt.A256CBCHS512}
+
+	for _, test := range tests {
+		for _, enc := range encs {
+			tok := jwt.NewJWE(test.alg, enc)
+			tok.Header().Set("kid", "test")
+			val, err := tok.Encrypt(rand.Reader, test.pub, []byte("hello world"))
+			if err != nil {
+				t.Errorf("%s/%s: failed to encrypt: %s", test.alg, enc, err)
+				continue
+			}
+
+			tok2, err := jwt.ParseJWE(val)
+			if err != nil {
+				t.Errorf("%s/%s: failed to parse: %s", test.alg, enc, err)
+				continue
+			}
+			if tok2.GetKeyId() != "test" {
+				t.Errorf("%s/%s: bad kid value", test.alg, enc)
+			}
+			res, err := tok2.Decrypt(test.priv, jwt.DecryptAlgo(test.alg), jwt.DecryptEnc(enc))
+			if err != nil {
+				t.Errorf("%s/%s: failed to decrypt: %s", test.alg, enc, err)
+				continue
+			}
+			if string(res) != "hello world" {
+				t.Errorf("%s/%s: unexpected plaintext: %q", test.alg, enc, res)
+			}
+		}
+	}
+}
+
+func TestJWEAlgoAllowed(t *testing.T) {
+	key := make([]byte, 16)
+	val, err := jwt.NewJWE(jwt.PBES2HS256A128KW, jwt.A128GCM).Encrypt(rand.Reader, key, []byte("hello"))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+	tok, err := jwt.ParseJWE(val)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	// the same symmetric key is accepted by PBES2, but the algorithm must
+	// be explicitly allowed
+	for _, opts := range [][]jwt.DecryptOption{
+		nil,
+		{jwt.DecryptAlgo(jwt.A128KW, jwt.Direct)},
+		{jwt.DecryptAlgo(jwt.PBES2HS256A128KW), jwt.DecryptEnc(jwt.A256GCM)},
+	} {
+		if _, err := tok.Decrypt(key, opts...); !errors.Is(err, jwt.ErrAlgNotAllowed) {
+			t.Errorf("expected ErrAlgNotAllowed, got %v", err)
+		}
+	}
+	if _, err := tok.Decrypt(key, jwt.DecryptAlgo(jwt.PBES2HS256A128KW), jwt.DecryptEnc(jwt.A128GCM)); err != nil {
+		t.Errorf("failed to decrypt: %s", err)
+	}
+}
+
+func TestJWERSAOAEPFailure(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	val, err := jwt.NewJWE(jwt.RSAOAEP256, jwt.A128GCM).Encrypt(rand.Reader, &rsaKey.PublicKey, []byte("hello"))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+
+	// a corrupted encrypted key fails the same way as a corrupted tag
+	for _, part := range []int{1, 4} {
+		parts := strings.Split(val, ".")
+		buf, _ := base64.RawURLEncoding.DecodeString(parts[part])
+		buf[0] ^= 1
+		parts[part] = base64.RawURLEncoding.EncodeToString(buf)
+
+		tok, err := jwt.ParseJWE(strings.Join(parts, "."))
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		if _, err := tok.Decrypt(rsaKey, jwt.DecryptAlgo(jwt.RSAOAEP256)); err != jwt.ErrDecryptionFailed {
+			t.Errorf("part %d: expected ErrDecryptionFailed, got %v", part, err)
+		}
+	}
+}
+
+func TestJWEDirect(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	tok := jwt.NewJWE(jwt.Direct, jwt.A128CBCHS256)
+	val, err := tok.Encrypt(nil, key, []byte("secret data"))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+
+	if _, err := jwt.ParseString(val); !errors.Is(err, jwt.ErrEncryptedToken) {
+		t.Errorf("ParseString should reject JWE, got %v", err)
+	}
+
+	tok2, err := jwt.ParseJWE(val)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	res, err := tok2.Decrypt(key, jwt.DecryptAlgo(jwt.Direct))
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+	if string(res) != "secret data" {
+		t.Errorf("unexpected plaintext: %q", res)
+	}
+
+	// wrong key size for enc
+	if _, err := tok2.Decrypt(key[:16], jwt.DecryptAlgo(jwt.Direct)); !errors.Is(err, jwt.ErrInvalidDecryptKey) {
+		t.Errorf("expected invalid key error, got %v", err)
+	}
+}
+
+func TestJWEECDHESRFC7518(t *testing.T) {
+	// RFC 7518, Appendix C: Alice's ephemeral key and Bob's key, with the
+	// expected derived key for A128GCM
+	cek, _ := base64.RawURLEncoding.DecodeString("VqqN6vgjbSBcIijNcacQGg")
+
+	hdr := `{"alg":"ECDH-ES","enc":"A128GCM","apu":"QWxpY2U","apv":"Qm9i","epk":{"kty":"EC","crv":"P-256","x":"gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0","y":"SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps"}}`
+	hdrB64 := base64.RawURLEncoding.EncodeToString([]byte(hdr))
+
+	iv, ciphertext, tag, err := jwt.A128GCM.Encrypt(rand.Reader, cek, []byte("hello bob"), []byte(hdrB64))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+	enc := base64.RawURLEncoding.EncodeToString
+	tok, err := jwt.ParseJWE(hdrB64 + ".." + enc(iv) + "." + enc(ciphertext) + "." + enc(tag))
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	res, err := tok.Decrypt(Bob, jwt.DecryptAlgo(jwt.ECDHES))
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+	if string(res) != "hello bob" {
+		t.Errorf("unexpected plaintext: %q", res)
+	}
+}
+
+func TestJWEECDHES(t *testing.T) {
+	x25519, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	keys := []struct {
+		pub  any
+		priv any
+	}{
+		{Bob.Public(), Bob},
+		{p384.Public(), p384},
+		{x25519.PublicKey(), x25519},
+	}
+
+	for _, alg := range []jwt.KeyAlgo{jwt.ECDHES, jwt.ECDHESA128KW, jwt.ECDHESA256KW} {
+		for _, k := range keys {
+			tok := jwt.NewJWE(alg, jwt.A256GCM)
+			tok.Header().Set("apu", base64.RawURLEncoding.EncodeToString([]byte("Alice")))
+			val, err := tok.Encrypt(rand.Reader, k.pub, []byte("hello world"))
+			if err != nil {
+				t.Errorf("%s/%T: failed to encrypt: %s", alg, k.pub, err)
+				continue
+			}
+
+			tok2, err := jwt.ParseJWE(val)
+			if err != nil {
+				t.Errorf("%s/%T: failed to parse: %s", alg, k.pub, err)
+				continue
+			}
+			res, err := tok2.Decrypt(k.priv, jwt.DecryptAlgo(alg))
+			if err != nil {
+				t.Errorf("%s/%T: failed to decrypt: %s", alg, k.pub, err)
+				continue
+			}
+			if string(res) != "hello world" {
+				t.Errorf("%s/%T: unexpected plaintext: %q", alg, k.pub, res)
+			}
+
+			// using another key must fail
+			if _, err = tok2.Decrypt(Alice, jwt.DecryptAlgo(alg)); err == nil {
+				t.Errorf("%s/%T: decryption with wrong key succeeded", alg, k.pub)
+			}
+		}
+	}
+}
+
+func TestJWEPBES2(t *testing.T) {
+	tok := jwt.NewJWE(jwt.PBES2HS256A128KW, jwt.A128CBCHS256)
+	tok.Header().Set("p2c", 2000)
+	val, err := tok.Encrypt(rand.Reader, "correct horse battery staple", []byte("hello world"))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+
+	tok2, err := jwt.ParseJWE(val)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	res, err := tok2.Decrypt([]byte("correct horse battery staple"), jwt.DecryptAlgo(jwt.PBES2HS256A128KW))
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+	if string(res) != "hello world" {
+		t.Errorf("unexpected plaintext: %q", res)
+	}
+	if _, err := tok2.Decrypt("wrong password", jwt.DecryptAlgo(jwt.PBES2HS256A128KW)); !errors.Is(err, jwt.ErrDecryptionFailed) {
+		t.Errorf("expected decryption failure, got %v", err)
+	}
+
+	// iteration count above the accepted bounds must be rejected
+	if _, err := tok2.Decrypt("correct horse battery staple", jwt.DecryptAlgo(jwt.PBES2HS256A128KW), jwt.DecryptMaxIterations(1500)); !errors.Is(err, jwt.ErrInvalidToken) {
+		t.Errorf("expected p2c bound failure, got %v", err)
+	}
+
+	// the default maximum is lower than what can be set when encrypting
+	tok = jwt.NewJWE(jwt.PBES2HS256A128KW, jwt.A128CBCHS256)
+	tok.Header().Set("p2c", 200000)
+	val, err = tok.Encrypt(rand.Reader, "correct horse battery staple", []byte("hello world"))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+	tok2, err = jwt.ParseJWE(val)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if _, err := tok2.Decrypt("correct horse battery staple", jwt.DecryptAlgo(jwt.PBES2HS256A128KW)); !errors.Is(err, jwt.ErrInvalidToken) {
+		t.Errorf("expected p2c bound failure, got %v", err)
+	}
+	if _, err := tok2.Decrypt("correct horse battery staple", jwt.DecryptAlgo(jwt.PBES2HS256A128KW), jwt.DecryptMaxIterations(200000)); err != nil {
+		t.Errorf("failed to decrypt: %s", err)
+	}
+}
+
+func TestJWKEncrypt(t *testing.T) {
+	enc, err := Alice.Encrypt(rand.Reader, jwt.PBES2HS512A256KW, jwt.A256GCM, "passphrase")
+	if err != nil {
+		t.Fatalf("failed to encrypt key: %s", err)
+	}
+
+	k, err := jwt.DecryptJWK(enc, "passphrase", jwt.DecryptAlgo(jwt.PBES2HS512A256KW))
+	if err != nil {
+		t.Fatalf("failed to decrypt key: %s", err)
+	}
+	if !Alice.PrivateKey.(*ecdsa.PrivateKey).Equal(k.PrivateKey) {
+		t.Errorf("decrypted key does not match")
+	}
+}
+
+func TestNestedJWT(t *testing.T) {
+	tok := jwt.New(jwt.ES256)
+	tok.Payload().Set("iss", "alice")
+	enc, err := tok.SignAndEncrypt(rand.Reader, Alice, jwt.NewJWE(jwt.ECDHESA128KW, jwt.A128GCM), Bob.Public())
+	if err != nil {
+		t.Fatalf("failed to sign and encrypt: %s", err)
+	}
+	dec := []jwt.DecryptOption{jwt.DecryptAlgo(jwt.ECDHESA128KW, jwt.ECDHES)}
+
+	res, err := jwt.DecryptAndVerify(enc, Bob, dec, jwt.VerifyAlgo(jwt.ES256), jwt.VerifySignature(Alice.Public()))
+	if err != nil {
+		t.Fatalf("failed to decrypt and verify: %s", err)
+	}
+	if res.Payload().GetString("iss") != "alice" {
+		t.Errorf("invalid value in body")
+	}
+	if res.Outer() == nil || res.Outer().Header().Get("cty") != "JWT" {
+		t.Errorf("outer JWE not available")
+	}
+
+	// signature from another key must fail
+	if _, err = jwt.DecryptAndVerify(enc, Bob, dec, jwt.VerifySignature(Bob.Public())); err == nil {
+		t.Errorf("verification with wrong key succeeded")
+	}
+
+	// a signed token that was not encrypted must be rejected
+	signed, err := tok.Sign(rand.Reader, Alice)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if _, err = jwt.DecryptAndVerify(signed, Bob, dec, jwt.VerifyAlgo(jwt.ES256), jwt.VerifySignature(Alice.Public())); !errors.Is(err, jwt.ErrNotEncryptedToken) {
+		t.Errorf("expected ErrNotEncryptedToken, got %v", err)
+	}
+
+	// non nested JWE
+	jwe := jwt.NewJWE(jwt.ECDHES, jwt.A128GCM)
+	enc, err = jwe.Encrypt(rand.Reader, Bob.Public(), []byte("not a token"))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+	if _, err = jwt.ParseEncrypted(enc, Bob, dec...); !errors.Is(err, jwt.ErrNotNestedToken) {
+		t.Errorf("expected ErrNotNestedToken, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// jwkCurve returns the elliptic curve for the given crv value
+func jwkCurve(crv any) (elliptic.Curve, bool) {
+	switch crv {
+	case "P-224":
+		return elliptic.P224(), true
+	case "P-256":
+		return elliptic.P256(), true
+	case "P-384":
+		return elliptic.P384(), true
+	case "P-521":
+		return elliptic.P521(), true
+	case "secp256k1":
+		return Secp256k1(), true
+	}
+	return nil, false
+}
+
+// jwkECKey reads an EC JWK as described in RFC 7518, Section 6.2, ensuring
+// the point is on the curve and, for private keys, that d matches it. priv is
+// nil for public keys.
+func jwkECKey(values map[string]any) (*ecdsa.PrivateKey, *ecdsa.PublicKey, error) {
+	crv, ok := jwkCurve(values["crv"])
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: EC curve %v", ErrUnsupportedKey, values["crv"])
+	}
+	size := (crv.Params().BitSize + 7) / 8
+
+	// x, y and d in private key, only x and y if public
+	coord := make(map[string]*big.Int)
+	for _, k := range []string{"x", "y"} {
+		buf, err := jwkBase64ToBytes(values[k])
+		if err != nil {
+			return nil, nil, &InvalidKeyError{Kty: "EC", Param: k, Reason: err.Error()}
+		}
+		if len(buf) != size {
+			return nil, nil, &InvalidKeyError{Kty: "EC", Param: k, Reason: "invalid length for curve"}
+		}
+		coord[k] = new(big.Int).SetBytes(buf)
+	}
+	pub := &ecdsa.PublicKey{Curve: crv, X: coord["x"], Y: coord["y"]}
+	if !crv.IsOnCurve(pub.X, pub.Y) {
+		return nil, nil, &InvalidKeyError{Kty: "EC", Reason: "point is not on curve"}
+	}
+
+	dA, ok := values["d"]
+	if !ok {
+		return nil, pub, nil
+	}
+
+	// private key
+	buf, err := jwkBase64ToBytes(dA)
+	if err != nil {
+		return nil, nil, &InvalidKeyError{Kty: "EC", Param: "d", Reason: err.Error()}
+	}
+	if len(buf) != size {
+		return nil, nil, &InvalidKeyError{Kty: "EC", Param: "d", Reason: "invalid length for curve"}
+	}
+	d := new(big.Int).SetBytes(buf)
+	if d.Sign() == 0 || d.Cmp(crv.Params().N) >= 0 {
+		return nil, nil, &InvalidKeyError{Kty: "EC", Param: "d", Reason: "value out of range"}
+	}
+	x, y := crv.ScalarBaseMult(buf)
+	if x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+		return nil, nil, &InvalidKeyError{Kty: "EC", Param: "d", Reason: "private key does not match public point"}
+	}
+
+	priv := &ecdsa.PrivateKey{PublicKey: *pub, D: d}
+	return priv, &priv.PublicKey, nil
+}
+
+// jwkECValues returns the values of an EC key, with coordinates and d padded
+// to the size of the curve as required by RFC 7518, Section 6.2.1.
+func jwkECValues(pub *ecdsa.PublicKey, d *big.Int) map[string]any {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	res := map[string]any{
+		"kty": "EC",
+		"crv": pub.Curve.Params().Name,
+		"x":   jwkFixedBase64(pub.X, size),
+		"y":   jwkFixedBase64(pub.Y, size),
+	}
+	if d != nil {
+		res["d"] = jwkFixedBase64(d, size)
+	}
+	return res
+}
+
+// jwkFixedBase64 encodes v as a big endian value of exactly size bytes
+func jwkFixedBase64(v *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(v.FillBytes(make([]byte, size)))
+}
@@ -0,0 +1,67 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestJWKECValidation(t *testing.T) {
+	alice := Alice.ExportRequiredValues()
+	bob := Bob.ExportRequiredValues()
+
+	if _, ok := Alice.PublicKey.(*ecdsa.PublicKey); !ok {
+		t.Errorf("bad public key type %T", Alice.PublicKey)
+	}
+
+	tests := map[string]map[string]any{
+		"d mismatch":   {"kty": "EC", "crv": "P-256", "x": alice["x"], "y": alice["y"], "d": bob["d"]},
+		"not on curve": {"kty": "EC", "crv": "P-256", "x": alice["x"], "y": bob["y"]},
+		"short x":      {"kty": "EC", "crv": "P-256", "x": "AQ", "y": alice["y"]},
+		"long x":       {"kty": "EC", "crv": "P-256", "x": "AAAA" + alice["x"].(string), "y": alice["y"]},
+	}
+	for name, values := range tests {
+		var e *jwt.InvalidKeyError
+		if err := (&jwt.JWK{}).ApplyValues(values); !errors.As(err, &e) {
+			t.Errorf("%s: expected InvalidKeyError, got %v", name, err)
+		}
+	}
+
+	unknown := map[string]any{"kty": "EC", "crv": "P-999", "x": alice["x"], "y": alice["y"]}
+	if err := (&jwt.JWK{}).ApplyValues(unknown); !errors.Is(err, jwt.ErrUnsupportedKey) {
+		t.Errorf("unknown crv: expected ErrUnsupportedKey, got %v", err)
+	}
+}
+
+func TestJWKECPadding(t *testing.T) {
+	// find a key which x coordinate has a leading zero byte
+	var priv *ecdsa.PrivateKey
+	for priv == nil || priv.X.BitLen() > 248 {
+		var err error
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %s", err)
+		}
+	}
+
+	values := (&jwt.JWK{PrivateKey: priv}).ExportRequiredValues()
+	for _, k := range []string{"x", "y", "d"} {
+		buf, err := base64.RawURLEncoding.DecodeString(values[k].(string))
+		if err != nil || len(buf) != 32 {
+			t.Errorf("bad length for %s: %d", k, len(buf))
+		}
+	}
+
+	key := &jwt.JWK{}
+	if err := key.ApplyValues(values); err != nil {
+		t.Fatalf("failed to import padded key: %s", err)
+	}
+	if !priv.Equal(key.PrivateKey) {
+		t.Errorf("imported key does not match")
+	}
+}
@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// GenerateKey returns a new key for the given signature algorithm as a JWK,
+// with alg and use set, and kid set to the RFC 7638 thumbprint of the key.
+// Keys generated are:
+//
+//   - HS*: a random secret of the size of the hash
+//   - RS* and PS*: a RSA key of 2048 bits (RS256, PS256) or 3072 bits
+//   - ES*: an ECDSA key on the curve of the algo, except for ES256K (see
+//     Secp256k1)
+//   - EdDSA: an Ed25519 key
+//
+// A custom Algo can support key generation by implementing a
+// GenerateKey(rand io.Reader) (crypto.PrivateKey, error) method.
+func GenerateKey(alg Algo) (*JWK, error) {
+	gen, ok := alg.(interface {
+		GenerateKey(rand io.Reader) (crypto.PrivateKey, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("%w: key generation not supported for %s", ErrUnknownAlg, alg)
+	}
+
+	priv, err := gen.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &JWK{
+		PrivateKey: priv,
+		Algo:       alg.String(),
+		Use:        "sig",
+	}
+	res.PublicKey = res.Public()
+
+	if err := res.setThumbprintKeyID(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// rsaKeySize returns the size of RSA keys generated for the given hash
+func rsaKeySize(h crypto.Hash) int {
+	if h.Size() > sha256.Size {
+		return 3072
+	}
+	return 2048
+}
@@ -0,0 +1,66 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha512"
+	"encoding/json"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestGenerateKey(t *testing.T) {
+	for _, alg := range []jwt.Algo{jwt.HS256, jwt.HS512, jwt.RS256, jwt.PS512, jwt.ES256, jwt.ES384, jwt.ES512, jwt.EdDSA} {
+		key, err := jwt.GenerateKey(alg)
+		if err != nil {
+			t.Errorf("%s: failed to generate key: %s", alg, err)
+			continue
+		}
+		if key.Algo != alg.String() || key.Use != "sig" || key.KeyID == "" {
+			t.Errorf("%s: bad key values alg=%s use=%s kid=%s", alg, key.Algo, key.Use, key.KeyID)
+		}
+		if k, ok := key.PrivateKey.(*rsa.PrivateKey); ok && alg == jwt.PS512 && k.N.BitLen() != 3072 {
+			t.Errorf("%s: bad RSA key size %d", alg, k.N.BitLen())
+		}
+
+		// the key can be exported and used to verify signatures
+		exported := key.ExportRequiredValues()
+		if key.KeyType() != "oct" {
+			exported = (&jwt.JWK{PublicKey: key.Public()}).ExportRequiredPublicValues()
+		}
+		buf, err := json.Marshal(exported)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %s", alg, err)
+		}
+		pub := parseJwk(buf)
+		if pub.ThumbprintHex(crypto.SHA256) != key.ThumbprintHex(crypto.SHA256) {
+			t.Errorf("%s: thumbprint mismatch", alg)
+		}
+
+		tok := jwt.New(alg)
+		tok.Header().Set("kid", key.KeyID)
+		sign, err := tok.Sign(rand.Reader, key)
+		if err != nil {
+			t.Errorf("%s: failed to sign: %s", alg, err)
+			continue
+		}
+		tok2, err := jwt.ParseString(sign)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %s", alg, err)
+		}
+		set := &jwt.JWKSet{Keys: []*jwt.JWK{pub}}
+		pub.KeyID = key.KeyID
+		if err := tok2.Verify(jwt.VerifyAlgo(alg), jwt.VerifySignatureWith(set.LookupKey)); err != nil {
+			t.Errorf("%s: failed to verify: %s", alg, err)
+		}
+	}
+
+	if _, err := jwt.GenerateKey(jwt.None); err == nil {
+		t.Errorf("generating a key for none should fail")
+	}
+	if _, err := jwt.GenerateKey(jwt.ES256K); err == nil {
+		t.Errorf("generating a key for ES256K should fail")
+	}
+}
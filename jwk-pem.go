@@ -0,0 +1,175 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ParsePEM parses a PEM encoded key and returns it as a JWK, with kid set to
+// the RFC 7638 thumbprint of the key. Supported blocks are PRIVATE KEY
+// (PKCS#8), RSA PRIVATE KEY (PKCS#1), EC PRIVATE KEY (SEC1), PUBLIC KEY
+// (PKIX), RSA PUBLIC KEY (PKCS#1) and CERTIFICATE.
+//
+// If the data contains certificates, they are stored in the JWK's
+// Certificates, and the first certificate must match the key if any. If the
+// data only contains certificates, the key of the first one is used.
+func ParsePEM(data []byte) (*JWK, error) {
+	res := &JWK{}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "CERTIFICATE":
+			crt, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			res.Certificates = append(res.Certificates, crt)
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY", "PUBLIC KEY", "RSA PUBLIC KEY":
+			if res.PrivateKey != nil || res.PublicKey != nil {
+				return nil, errors.New("jwt: PEM data contains more than one key")
+			}
+			if err := res.applyDER(block.Type, block.Bytes); err != nil {
+				return nil, err
+			}
+		default:
+			// ignore other blocks, such as EC PARAMETERS
+		}
+	}
+
+	if err := res.finishDER(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ParseDER parses a DER encoded key or certificate, trying PKCS#8, PKCS#1,
+// SEC1 and PKIX formats, and returns it as a JWK similar to ParsePEM.
+func ParseDER(der []byte) (*JWK, error) {
+	res := &JWK{}
+	if crt, err := x509.ParseCertificate(der); err == nil {
+		res.Certificates = []*x509.Certificate{crt}
+	} else if err := res.applyDER("", der); err != nil {
+		return nil, err
+	}
+
+	if err := res.finishDER(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// applyDER parses a key in the format given by the PEM block type typ, or
+// tries all formats if typ is empty.
+func (jwk *JWK) applyDER(typ string, der []byte) error {
+	var key any
+	var err error
+
+	switch typ {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(der)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(der)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(der)
+	case "":
+		parsers := []func([]byte) (any, error){
+			x509.ParsePKCS8PrivateKey,
+			func(b []byte) (any, error) { return x509.ParsePKCS1PrivateKey(b) },
+			func(b []byte) (any, error) { return x509.ParseECPrivateKey(b) },
+			x509.ParsePKIXPublicKey,
+			func(b []byte) (any, error) { return x509.ParsePKCS1PublicKey(b) },
+		}
+		for _, p := range parsers {
+			if key, err = p(der); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return errors.New("jwt: unsupported DER key format")
+		}
+	default:
+		return fmt.Errorf("jwt: unsupported PEM block type %s", typ)
+	}
+	if err != nil {
+		return err
+	}
+
+	if pub, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+		jwk.PrivateKey = key
+		jwk.PublicKey = pub.Public()
+	} else {
+		jwk.PublicKey = key
+	}
+	return nil
+}
+
+// finishDER completes a JWK parsed by ParsePEM or ParseDER
+func (jwk *JWK) finishDER() error {
+	if len(jwk.Certificates) > 0 {
+		if jwk.PublicKey == nil {
+			jwk.PublicKey = jwk.Certificates[0].PublicKey
+		} else if pub, ok := jwk.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(jwk.Certificates[0].PublicKey) {
+			return errors.New("jwt: certificate does not match key")
+		}
+	}
+	if jwk.PublicKey == nil {
+		return errors.New("jwt: no key found")
+	}
+	if pub, ok := jwk.PublicKey.(*rsa.PublicKey); ok && pub.N.BitLen() < RSAMinKeySize {
+		return fmt.Errorf("jwt: RSA key size %d is below the minimum of %d bits", pub.N.BitLen(), RSAMinKeySize)
+	}
+	return jwk.setThumbprintKeyID()
+}
+
+// MarshalDER returns the key in DER format, using PKCS#8 for private keys and
+// PKIX for public keys.
+func (jwk *JWK) MarshalDER() ([]byte, error) {
+	if jwk.PrivateKey != nil {
+		return x509.MarshalPKCS8PrivateKey(jwk.PrivateKey)
+	}
+	return x509.MarshalPKIXPublicKey(jwk.PublicKey)
+}
+
+// MarshalPEM returns the key in PEM format as a PRIVATE KEY (PKCS#8) or
+// PUBLIC KEY (PKIX) block, followed by the certificates if any.
+func (jwk *JWK) MarshalPEM() ([]byte, error) {
+	der, err := jwk.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	typ := "PUBLIC KEY"
+	if jwk.PrivateKey != nil {
+		typ = "PRIVATE KEY"
+	}
+
+	res := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	for _, crt := range jwk.Certificates {
+		res = append(res, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw})...)
+	}
+	return res, nil
+}
+
+// setThumbprintKeyID sets the kid of the key to its RFC 7638 thumbprint
+func (jwk *JWK) setThumbprintKeyID() error {
+	tp, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(tp)
+	return nil
+}
@@ -0,0 +1,150 @@
+package jwt_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestPEMRoundTrip(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	xKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	for _, priv := range []any{rsaKey, ecKey, edKey, xKey} {
+		pemData, err := (&jwt.JWK{PrivateKey: priv}).MarshalPEM()
+		if err != nil {
+			t.Fatalf("%T: failed to marshal PEM: %s", priv, err)
+		}
+		key, err := jwt.ParsePEM(pemData)
+		if err != nil {
+			t.Fatalf("%T: failed to parse PEM: %s", priv, err)
+		}
+		if key.KeyID == "" || key.PrivateKey == nil {
+			t.Errorf("%T: missing kid or private key", priv)
+		}
+
+		// JSON round trip then back to PEM gives the same key and kid
+		buf, err := json.Marshal(key)
+		if err != nil {
+			t.Fatalf("%T: failed to marshal JSON: %s", priv, err)
+		}
+		key2 := parseJwk(buf)
+		if key2.KeyID != key.KeyID {
+			t.Errorf("%T: kid mismatch after JSON round trip", priv)
+		}
+		pemData2, err := key2.MarshalPEM()
+		if err != nil {
+			t.Fatalf("%T: failed to marshal PEM: %s", priv, err)
+		}
+		key3, err := jwt.ParsePEM(pemData2)
+		if err != nil {
+			t.Fatalf("%T: failed to parse PEM: %s", priv, err)
+		}
+		if key3.KeyID != key.KeyID {
+			t.Errorf("%T: kid mismatch after PEM round trip", priv)
+		}
+
+		// public key only
+		der, err := (&jwt.JWK{PublicKey: key.Public()}).MarshalDER()
+		if err != nil {
+			t.Fatalf("%T: failed to marshal DER: %s", priv, err)
+		}
+		pub, err := jwt.ParseDER(der)
+		if err != nil {
+			t.Fatalf("%T: failed to parse DER: %s", priv, err)
+		}
+		if pub.PrivateKey != nil || pub.KeyID != key.KeyID {
+			t.Errorf("%T: bad public key import", priv)
+		}
+	}
+}
+
+func TestPEMFormats(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+
+	tests := []struct {
+		typ string
+		der []byte
+		pub any
+	}{
+		{"RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), &rsaKey.PublicKey},
+		{"RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey), &rsaKey.PublicKey},
+		{"EC PRIVATE KEY", sec1, &ecKey.PublicKey},
+	}
+	for _, test := range tests {
+		key, err := jwt.ParsePEM(pem.EncodeToMemory(&pem.Block{Type: test.typ, Bytes: test.der}))
+		if err != nil {
+			t.Errorf("%s: failed to parse: %s", test.typ, err)
+			continue
+		}
+		if !test.pub.(interface{ Equal(x crypto.PublicKey) bool }).Equal(key.PublicKey) {
+			t.Errorf("%s: public key mismatch", test.typ)
+		}
+		if _, err := jwt.ParseDER(test.der); err != nil {
+			t.Errorf("%s: failed to parse DER: %s", test.typ, err)
+		}
+	}
+
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	if _, err := jwt.ParseDER(x509.MarshalPKCS1PrivateKey(small)); err == nil {
+		t.Errorf("small RSA key should be rejected")
+	}
+	if _, err := jwt.ParsePEM([]byte("not a PEM file")); err == nil {
+		t.Errorf("parsing invalid data should fail")
+	}
+	if _, err := (&jwt.JWK{PrivateKey: []byte("secret")}).MarshalPEM(); err == nil {
+		t.Errorf("exporting a symmetric key should fail")
+	}
+}
+
+func TestPEMCertificate(t *testing.T) {
+	ca, caKey := testCertificate(t, "Test CA", nil, nil)
+	leaf, leafKey := testCertificate(t, "Test signer", ca, caKey)
+
+	pemData, err := (&jwt.JWK{PrivateKey: leafKey, Certificates: []*x509.Certificate{leaf, ca}}).MarshalPEM()
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	key, err := jwt.ParsePEM(pemData)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if len(key.Certificates) != 2 || !key.Certificates[1].Equal(ca) || !leafKey.Equal(key.PrivateKey) {
+		t.Errorf("bad key imported from PEM")
+	}
+
+	// certificate only
+	key, err = jwt.ParseDER(leaf.Raw)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	if key.PrivateKey != nil || !leafKey.PublicKey.Equal(key.PublicKey) {
+		t.Errorf("bad key imported from certificate")
+	}
+
+	// certificate that does not match the key
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(other)
+	bad := append(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})...)
+	if _, err := jwt.ParsePEM(bad); err == nil {
+		t.Errorf("mismatched certificate should be rejected")
+	}
+	if !bytes.Contains(pemData, []byte("BEGIN CERTIFICATE")) {
+		t.Errorf("certificates missing from PEM output")
+	}
+}
@@ -0,0 +1,229 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// RSAMinKeySize is the minimum size in bits of the modulus of RSA keys
+// imported from JWK or PEM. RFC 7518 requires keys of 2048 bits or larger.
+var RSAMinKeySize = 2048
+
+var bigOne = big.NewInt(1)
+
+// jwkRSAPublicKey reads the public values of a RSA JWK (n, e)
+func jwkRSAPublicKey(values map[string]any) (*rsa.PublicKey, error) {
+	// e=AQAB = 0x010001 = 65537
+	eB, err := jwkBase64ToBigInt(values["e"])
+	if err != nil {
+		return nil, fmt.Errorf("while reading e: %w", err)
+	}
+	if !eB.IsInt64() || eB.Int64() > math.MaxInt32 {
+		return nil, fmt.Errorf("value for e is too large")
+	}
+	if eB.Int64() < 3 || eB.Bit(0) == 0 {
+		return nil, fmt.Errorf("invalid value for e")
+	}
+	nB, err := jwkBase64ToBigInt(values["n"])
+	if err != nil {
+		return nil, fmt.Errorf("while reading n: %w", err)
+	}
+	if nB.BitLen() < RSAMinKeySize {
+		return nil, fmt.Errorf("%w: RSA key size %d is below the minimum of %d bits", ErrUnsupportedKey, nB.BitLen(), RSAMinKeySize)
+	}
+
+	return &rsa.PublicKey{N: nB, E: int(eB.Int64())}, nil
+}
+
+// jwkRSAPrivateKey reads the private values of a RSA JWK as described in RFC
+// 7518, Section 6.3.2. If the primes are not provided, they are recovered
+// from d.
+func jwkRSAPrivateKey(pub *rsa.PublicKey, values map[string]any) (*rsa.PrivateKey, error) {
+	d, err := jwkBase64ToBigInt(values["d"])
+	if err != nil {
+		return nil, fmt.Errorf("while reading d: %w", err)
+	}
+	res := &rsa.PrivateKey{PublicKey: *pub, D: d}
+
+	if _, ok := values["p"]; !ok {
+		for _, k := range []string{"q", "dp", "dq", "qi", "oth"} {
+			if _, ok := values[k]; ok {
+				return nil, fmt.Errorf("RSA private key has %s but no p", k)
+			}
+		}
+		p, q, err := rsaRecoverPrimes(pub.N, big.NewInt(int64(pub.E)), d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		res.Primes = []*big.Int{p, q}
+	} else {
+		// all of p, q, dp, dq and qi must be present
+		crt := make(map[string]*big.Int)
+		for _, k := range []string{"p", "q", "dp", "dq", "qi"} {
+			v, err := jwkBase64ToBigInt(values[k])
+			if err != nil {
+				return nil, fmt.Errorf("while reading %s: %w", k, err)
+			}
+			crt[k] = v
+		}
+		res.Primes = []*big.Int{crt["p"], crt["q"]}
+
+		// other primes info, for keys with more than two primes
+		var oth []*rsa.CRTValue
+		if othA, ok := values["oth"]; ok {
+			list, ok := othA.([]any)
+			if !ok || len(list) == 0 {
+				return nil, fmt.Errorf("invalid value for oth")
+			}
+			for n, o := range list {
+				info, ok := o.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("invalid value for oth[%d]", n)
+				}
+				v := &rsa.CRTValue{}
+				for k, ptr := range map[string]**big.Int{"r": &v.R, "d": &v.Exp, "t": &v.Coeff} {
+					if *ptr, err = jwkBase64ToBigInt(info[k]); err != nil {
+						return nil, fmt.Errorf("while reading oth[%d].%s: %w", n, k, err)
+					}
+				}
+				res.Primes = append(res.Primes, v.R)
+				oth = append(oth, v)
+			}
+		}
+
+		// ensure N is the product of the primes, and d is consistent with e
+		prod := big.NewInt(1)
+		de := new(big.Int).Mul(d, big.NewInt(int64(pub.E)))
+		for _, prime := range res.Primes {
+			if prime.Cmp(bigOne) <= 0 {
+				return nil, fmt.Errorf("invalid RSA private key: bad prime value")
+			}
+			prod.Mul(prod, prime)
+			if new(big.Int).Mod(de, new(big.Int).Sub(prime, bigOne)).Cmp(bigOne) != 0 {
+				return nil, fmt.Errorf("invalid RSA private key: d does not match e")
+			}
+		}
+		if prod.Cmp(pub.N) != 0 {
+			return nil, fmt.Errorf("invalid RSA private key: primes do not match n")
+		}
+
+		// ensure the CRT values are consistent with d and the primes
+		exp := rsaCRTValues(res)
+		if exp.Dp.Cmp(crt["dp"]) != 0 || exp.Dq.Cmp(crt["dq"]) != 0 || exp.Qinv.Cmp(crt["qi"]) != 0 {
+			return nil, fmt.Errorf("invalid RSA private key: CRT values do not match")
+		}
+		for n, v := range oth {
+			e := exp.CRTValues[n]
+			if e.Exp.Cmp(v.Exp) != 0 || e.Coeff.Cmp(v.Coeff) != 0 {
+				return nil, fmt.Errorf("invalid RSA private key: oth[%d] values do not match", n)
+			}
+		}
+	}
+
+	// Validate checks that N is the product of the primes and that d is
+	// consistent with e and the primes
+	if err := res.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RSA private key: %w", err)
+	}
+	res.Precompute()
+	return res, nil
+}
+
+// jwkRSAPrivateValues adds the private values of the key to res
+func jwkRSAPrivateValues(k *rsa.PrivateKey, res map[string]any) {
+	res["d"] = jwkBigIntToBase64(k.D)
+	if len(k.Primes) < 2 {
+		return
+	}
+	crt := rsaCRTValues(k)
+	res["p"] = jwkBigIntToBase64(k.Primes[0])
+	res["q"] = jwkBigIntToBase64(k.Primes[1])
+	res["dp"] = jwkBigIntToBase64(crt.Dp)
+	res["dq"] = jwkBigIntToBase64(crt.Dq)
+	res["qi"] = jwkBigIntToBase64(crt.Qinv)
+
+	if len(k.Primes) > 2 {
+		oth := make([]map[string]any, 0, len(k.Primes)-2)
+		for n, v := range crt.CRTValues {
+			oth = append(oth, map[string]any{
+				"r": jwkBigIntToBase64(k.Primes[n+2]),
+				"d": jwkBigIntToBase64(v.Exp),
+				"t": jwkBigIntToBase64(v.Coeff),
+			})
+		}
+		res["oth"] = oth
+	}
+}
+
+// rsaCRTValues computes the CRT values of the key without modifying it, as
+// Precompute cannot be used concurrently. The primes must not be nil or lower
+// than 2.
+func rsaCRTValues(k *rsa.PrivateKey) rsa.PrecomputedValues {
+	var res rsa.PrecomputedValues
+	p, q := k.Primes[0], k.Primes[1]
+
+	res.Dp = new(big.Int).Mod(k.D, new(big.Int).Sub(p, bigOne))
+	res.Dq = new(big.Int).Mod(k.D, new(big.Int).Sub(q, bigOne))
+	res.Qinv = new(big.Int).ModInverse(q, p)
+	if res.Qinv == nil {
+		res.Qinv = new(big.Int)
+	}
+
+	r := new(big.Int).Mul(p, q)
+	for _, prime := range k.Primes[2:] {
+		v := rsa.CRTValue{
+			Exp:   new(big.Int).Mod(k.D, new(big.Int).Sub(prime, bigOne)),
+			R:     new(big.Int).Set(r),
+			Coeff: new(big.Int).ModInverse(r, prime),
+		}
+		if v.Coeff == nil {
+			v.Coeff = new(big.Int)
+		}
+		res.CRTValues = append(res.CRTValues, v)
+		r.Mul(r, prime)
+	}
+	return res
+}
+
+// rsaRecoverPrimes recovers the two primes of n from the public and private
+// exponents, as described in NIST SP 800-56B Revision 2, Appendix C.2. The
+// primes are always returned with p > q, so that the recovered key does not
+// depend on which square root of 1 was found.
+func rsaRecoverPrimes(n, e, d *big.Int) (*big.Int, *big.Int, error) {
+	// k = d*e - 1 = 2^t * r with r odd
+	k := new(big.Int).Mul(d, e)
+	k.Sub(k, bigOne)
+	if k.Sign() <= 0 || k.Bit(0) != 0 {
+		return nil, nil, errors.New("d is not consistent with e")
+	}
+	r := new(big.Int).Rsh(k, k.TrailingZeroBits())
+	nMinus1 := new(big.Int).Sub(n, bigOne)
+
+	for g := int64(2); g < 100; g++ {
+		y := new(big.Int).Exp(big.NewInt(g), r, n)
+		if y.Cmp(bigOne) == 0 || y.Cmp(nMinus1) == 0 {
+			continue
+		}
+		for i := uint(0); i < k.TrailingZeroBits(); i++ {
+			x := new(big.Int).Mul(y, y)
+			x.Mod(x, n)
+			if x.Cmp(bigOne) == 0 {
+				// y is a non-trivial square root of 1 mod n
+				p := new(big.Int).GCD(nil, nil, new(big.Int).Sub(y, bigOne), n)
+				q := new(big.Int).Div(n, p)
+				if p.Cmp(q) < 0 {
+					p, q = q, p
+				}
+				return p, q, nil
+			}
+			if x.Cmp(nMinus1) == 0 {
+				break
+			}
+			y = x
+		}
+	}
+	return nil, nil, errors.New("failed to recover primes from d")
+}
@@ -0,0 +1,117 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestJWKRSA(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	values := (&jwt.JWK{PrivateKey: priv}).ExportRequiredValues()
+	for _, k := range []string{"n", "e", "d", "p", "q", "dp", "dq", "qi"} {
+		if _, ok := values[k]; !ok {
+			t.Errorf("exported key is missing %s", k)
+		}
+	}
+
+	key := &jwt.JWK{}
+	if err := key.ApplyValues(values); err != nil {
+		t.Fatalf("failed to import key: %s", err)
+	}
+	if !priv.Equal(key.PrivateKey) {
+		t.Errorf("imported key does not match")
+	}
+	if k := key.PrivateKey.(*rsa.PrivateKey); k.Precomputed.Dp == nil {
+		t.Errorf("imported key was not precomputed")
+	}
+	if _, ok := key.PublicKey.(*rsa.PublicKey); !ok {
+		t.Errorf("bad public key type %T", key.PublicKey)
+	}
+	tp1, _ := key.Thumbprint(crypto.SHA256)
+	tp2, _ := (&jwt.JWK{PublicKey: &priv.PublicKey}).Thumbprint(crypto.SHA256)
+	if tp1 == nil || string(tp1) != string(tp2) {
+		t.Errorf("thumbprint of private key does not match public key")
+	}
+
+	// primes are recovered if only d is provided
+	short := map[string]any{"kty": "RSA", "n": values["n"], "e": values["e"], "d": values["d"]}
+	key = &jwt.JWK{}
+	if err := key.ApplyValues(short); err != nil {
+		t.Fatalf("failed to import key without primes: %s", err)
+	}
+	// recovered primes are always ordered with p > q
+	p, q := priv.Primes[0], priv.Primes[1]
+	if p.Cmp(q) < 0 {
+		p, q = q, p
+	}
+	sorted := &rsa.PrivateKey{PublicKey: priv.PublicKey, D: priv.D, Primes: []*big.Int{p, q}}
+	if !sorted.Equal(key.PrivateKey) {
+		t.Errorf("imported key without primes does not match")
+	}
+
+	// inconsistent values are rejected
+	for _, k := range []string{"dp", "qi", "p"} {
+		bad := make(map[string]any)
+		for k, v := range values {
+			bad[k] = v
+		}
+		bad[k] = values["dq"]
+		if err := (&jwt.JWK{}).ApplyValues(bad); err == nil {
+			t.Errorf("key with bad %s should be rejected", k)
+		}
+	}
+	partial := map[string]any{"kty": "RSA", "n": values["n"], "e": values["e"], "d": values["d"], "p": values["p"]}
+	if err := (&jwt.JWK{}).ApplyValues(partial); err == nil {
+		t.Errorf("key with partial CRT values should be rejected")
+	}
+}
+
+func TestJWKRSAMultiPrime(t *testing.T) {
+	priv, err := rsa.GenerateMultiPrimeKey(rand.Reader, 3, 2048)
+	if err != nil {
+		t.Skipf("multi-prime keys not supported: %s", err)
+	}
+	buf, err := json.Marshal(&jwt.JWK{PrivateKey: priv})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	key := parseJwk(buf)
+	if !priv.Equal(key.PrivateKey) {
+		t.Errorf("imported key does not match")
+	}
+}
+
+func TestJWKRSAMinSize(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	values := (&jwt.JWK{PublicKey: &priv.PublicKey}).ExportRequiredPublicValues()
+	if err := (&jwt.JWK{}).ApplyValues(values); !errors.Is(err, jwt.ErrUnsupportedKey) {
+		t.Errorf("1024 bits key should be rejected, got %v", err)
+	}
+
+	// sets only skip the small key
+	buf, err := json.Marshal(&jwt.JWKSet{Keys: []*jwt.JWK{{PublicKey: &priv.PublicKey, KeyID: "small"}, {PublicKey: Alice.Public(), KeyID: "alice"}}})
+	if err != nil {
+		t.Fatalf("failed to marshal set: %s", err)
+	}
+	set, err := jwt.ParseJWKSet(buf)
+	if err != nil {
+		t.Fatalf("failed to parse set: %s", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != "alice" {
+		t.Errorf("small key should have been skipped")
+	}
+}
@@ -0,0 +1,200 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JWKSet is a set of keys as defined in RFC 7517, Section 5, typically
+// published by identity providers on a jwks_uri.
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// ParseJWKSet parses a JWK Set in JSON format. Keys that cannot be used
+// because their kty, crv or alg value is not supported, or RSA keys smaller
+// than RSAMinKeySize, are skipped as recommended in RFC 7517, Section 5, while
+// keys with invalid values cause an error.
+func ParseJWKSet(buf []byte) (*JWKSet, error) {
+	res := &JWKSet{}
+	if err := json.Unmarshal(buf, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *JWKSet) UnmarshalJSON(v []byte) error {
+	var tmp struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := json.Unmarshal(v, &tmp); err != nil {
+		return err
+	}
+	if tmp.Keys == nil {
+		return fmt.Errorf("JWK Set object requires keys attribute")
+	}
+
+	s.Keys = make([]*JWK, 0, len(tmp.Keys))
+	for n, values := range tmp.Keys {
+		if alg, ok := values["alg"].(string); ok && parseAlgo(alg) == nil && parseKeyAlgo(alg) == nil {
+			// skip keys for unsupported algorithms
+			continue
+		}
+		k := &JWK{}
+		if err := k.ApplyValues(values); err != nil {
+			if errors.Is(err, ErrUnsupportedKey) {
+				continue
+			}
+			return fmt.Errorf("while reading key %d of set: %w", n, err)
+		}
+		s.Keys = append(s.Keys, k)
+	}
+	return nil
+}
+
+func (s *JWKSet) MarshalJSON() ([]byte, error) {
+	keys := s.Keys
+	if keys == nil {
+		keys = []*JWK{}
+	}
+	return json.Marshal(map[string]any{"keys": keys})
+}
+
+// Public returns a copy of the set that can be published, for example on a
+// jwks_uri: symmetric (oct) keys are dropped and private keys are removed from
+// the other keys.
+func (s *JWKSet) Public() *JWKSet {
+	res := &JWKSet{Keys: []*JWK{}}
+	for _, k := range s.Keys {
+		pub := k.Public()
+		if pub == nil {
+			continue
+		}
+		c := *k
+		c.PrivateKey = nil
+		c.PublicKey = pub
+		res.Keys = append(res.Keys, &c)
+	}
+	return res
+}
+
+// Get returns the first key with the given kid, or nil if none was found.
+func (s *JWKSet) Get(kid string) *JWK {
+	for _, k := range s.Keys {
+		if k.KeyID == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// Filter returns a new set containing only the keys matching the passed kty,
+// use and alg values. Empty values match any key, and keys which do not
+// specify use or alg match any value.
+//
+// Example use: set.Filter("RSA", "sig", "")
+func (s *JWKSet) Filter(kty, use, alg string) *JWKSet {
+	res := &JWKSet{}
+	for _, k := range s.Keys {
+		if k.match(kty, use, alg) {
+			res.Keys = append(res.Keys, k)
+		}
+	}
+	return res
+}
+
+// LookupKey is a KeyFunc that returns the key matching the token's kid and
+// alg values, suitable for signing. If more than one key matches, typically
+// because the token has no kid, all of them are returned as PublicKeys so
+// VerifySignature will try each of them.
+//
+// Example use: tok.Verify(VerifyAlgo(RS256), VerifySignatureWith(set.LookupKey))
+func (s *JWKSet) LookupKey(ctx context.Context, tok *Token) (crypto.PublicKey, error) {
+	alg := tok.Header().Get("alg")
+	kid := tok.GetKeyId()
+
+	var keys PublicKeys
+	for _, k := range s.Keys {
+		if kid != "" && k.KeyID != kid {
+			continue
+		}
+		if !k.match(algoKeyType(alg), "sig", alg) {
+			continue
+		}
+		if len(k.KeyOps) > 0 && !stringsContain(k.KeyOps, "verify") {
+			continue
+		}
+		if secret, ok := symmetricKey(k); ok {
+			// HMAC verification requires the secret
+			keys = append(keys, secret)
+			continue
+		}
+		keys = append(keys, k.Public())
+	}
+
+	switch len(keys) {
+	case 0:
+		return nil, fmt.Errorf("%w: kid=%q alg=%s", ErrKeyNotFound, kid, alg)
+	case 1:
+		return keys[0], nil
+	}
+	return keys, nil
+}
+
+// match returns true if the key matches the passed values, see JWKSet.Filter
+func (jwk *JWK) match(kty, use, alg string) bool {
+	if kty != "" && jwk.KeyType() != kty {
+		return false
+	}
+	if use != "" && jwk.Use != "" && jwk.Use != use {
+		return false
+	}
+	if alg != "" && jwk.Algo != "" && jwk.Algo != alg {
+		return false
+	}
+	return true
+}
+
+// KeyType returns the kty value of the key, such as "RSA" or "EC".
+func (jwk *JWK) KeyType() string {
+	if _, ok := symmetricKey(jwk); ok {
+		return "oct"
+	}
+	switch k := jwk.Public().(type) {
+	case *rsa.PublicKey:
+		return "RSA"
+	case *ecdsa.PublicKey:
+		return "EC"
+	case ed25519.PublicKey:
+		return "OKP"
+	case *ecdh.PublicKey:
+		if k.Curve() == ecdh.X25519() {
+			return "OKP"
+		}
+	}
+	return ""
+}
+
+// algoKeyType returns the kty of keys used with the given signature algo, or
+// an empty string if unknown.
+func algoKeyType(alg string) string {
+	switch {
+	case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "PS"):
+		return "RSA"
+	case strings.HasPrefix(alg, "ES"):
+		return "EC"
+	case alg == "EdDSA":
+		return "OKP"
+	case strings.HasPrefix(alg, "HS"):
+		return "oct"
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestJWKSet(t *testing.T) {
+	set, err := jwt.ParseJWKSet([]byte(`{"keys":[
+		{"kty":"EC","crv":"P-256","kid":"alice","use":"sig","x":"gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0","y":"SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps"},
+		{"kty":"EC","crv":"P-256","kid":"bob","use":"enc","x":"weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ","y":"e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck"},
+		{"kty":"unknown","kid":"skipped"},
+		{"kty":"RSA","kid":"rsa","alg":"RS256","e":"AQAB","n":"ofgWCuLjybRlzo0tZWJjNiuSfb4p4fAkd_wWJcyQoTbji9k0l8W26mPddxHmfHQp-Vaw-4qPCJrcS2mJPMEzP1Pt0Bm4d4QlL-yRT-SFd2lZS-pCgNMsD1W_YpRPEwOWvG6b32690r2jZ47soMZo9wGzjb_7OMg0LOL-bSf63kpaSHSXndS5z5rexMdbBYUsLA9e-KXBdQOS-UTo7WTBEMa2R2CapHg665xsmtdVMTBQY4uDZlxvb3qCo5ZwKh9kG4LT6_I5IhlJH7aGhyxXFvUK-DWNmoudF8NAco9_h9iaGNj8q2ethFkMLs91kzk2PAcDTW9gb54h4FRWyuXpoQ"}
+	]}`))
+	if err != nil {
+		t.Fatalf("failed to parse set: %s", err)
+	}
+	if len(set.Keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d", len(set.Keys))
+	}
+	if k := set.Get("bob"); k == nil || !k.Public().(*ecdsa.PublicKey).Equal(Bob.Public()) {
+		t.Errorf("failed to get key bob")
+	}
+	if set.Get("skipped") != nil {
+		t.Errorf("unsupported key should have been skipped")
+	}
+	if l := set.Filter("EC", "sig", "").Keys; len(l) != 1 || l[0].KeyID != "alice" {
+		t.Errorf("bad filter result for EC sig")
+	}
+	if l := set.Filter("", "", "ES256").Keys; len(l) != 2 {
+		t.Errorf("bad filter result for ES256: %d keys", len(l))
+	}
+
+	// marshal and parse again
+	buf, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal set: %s", err)
+	}
+	set2, err := jwt.ParseJWKSet(buf)
+	if err != nil || len(set2.Keys) != 3 || set2.Get("rsa") == nil {
+		t.Errorf("failed to parse marshaled set: %v", err)
+	}
+
+	for _, kid := range []string{"alice", "bob", ""} {
+		tok := jwt.New(jwt.ES256)
+		if kid != "" {
+			tok.Header().Set("kid", kid)
+		}
+		sign, err := tok.Sign(nil, Alice)
+		if err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		tok2, err := jwt.ParseString(sign)
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		err = tok2.Verify(jwt.VerifyAlgo(jwt.ES256), jwt.VerifySignatureWith(set.LookupKey))
+		switch kid {
+		case "bob":
+			// bob's key is only for encryption
+			if !errors.Is(err, jwt.ErrKeyNotFound) {
+				t.Errorf("expected key not found error, got %v", err)
+			}
+		default:
+			if err != nil {
+				t.Errorf("failed to verify token with kid=%q: %s", kid, err)
+			}
+		}
+	}
+}
+
+func TestJWKSetNoKid(t *testing.T) {
+	set := &jwt.JWKSet{Keys: []*jwt.JWK{{PrivateKey: []byte("secret1")}, {PrivateKey: []byte("secret2")}, {PublicKey: Alice.Public()}}}
+
+	for _, key := range []string{"secret1", "secret2", "secret3"} {
+		tok := jwt.New(jwt.HS256)
+		sign, err := tok.Sign(nil, []byte(key))
+		if err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		tok2, err := jwt.ParseString(sign)
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		if pub, err := set.LookupKey(context.Background(), tok2); err != nil {
+			t.Errorf("failed to lookup key: %s", err)
+		} else if keys, ok := pub.(jwt.PublicKeys); !ok || len(keys) != 2 {
+			t.Errorf("expected both oct keys, got %T", pub)
+		}
+
+		// every matching key is tried
+		err = tok2.Verify(jwt.VerifyAlgo(jwt.HS256), jwt.VerifySignatureWith(set.LookupKey))
+		switch key {
+		case "secret3":
+			if !errors.Is(err, jwt.ErrInvalidSignature) {
+				t.Errorf("expected invalid signature error, got %v", err)
+			}
+		default:
+			if err != nil {
+				t.Errorf("failed to verify token signed with %s: %s", key, err)
+			}
+		}
+	}
+}
+
+func TestJWKSetPublic(t *testing.T) {
+	set := &jwt.JWKSet{Keys: []*jwt.JWK{Alice, {PrivateKey: []byte("secret"), KeyID: "hmac"}, {PublicKey: Bob.Public(), KeyID: "bob"}}}
+	pub := set.Public()
+	if len(pub.Keys) != 2 || pub.Get("hmac") != nil {
+		t.Fatalf("oct key should have been dropped, got %d keys", len(pub.Keys))
+	}
+	buf, err := json.Marshal(pub)
+	if err != nil {
+		t.Fatalf("failed to marshal set: %s", err)
+	}
+	var values struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := json.Unmarshal(buf, &values); err != nil {
+		t.Fatalf("failed to unmarshal set: %s", err)
+	}
+	for _, k := range values.Keys {
+		if _, ok := k["d"]; ok {
+			t.Errorf("private value exported for kid=%v", k["kid"])
+		}
+	}
+	if Alice.PrivateKey == nil {
+		t.Errorf("original key should not be modified")
+	}
+}
+
+func TestJWKSetInvalid(t *testing.T) {
+	// unsupported kty, crv and alg values are skipped
+	set, err := jwt.ParseJWKSet([]byte(`{"keys":[
+		{"kty":"unknown","kid":"kty"},
+		{"kty":"OKP","crv":"Ed448","kid":"crv","x":"AAAA"},
+		{"kty":"oct","alg":"HS1024","kid":"alg","k":"c2VjcmV0"},
+		{"kty":"oct","alg":"HS256","kid":"hmac","k":"c2VjcmV0"}
+	]}`))
+	if err != nil {
+		t.Fatalf("failed to parse set: %s", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != "hmac" {
+		t.Errorf("unsupported keys should have been skipped")
+	}
+
+	// invalid keys cause an error
+	for _, key := range []string{
+		`{"kty":"EC","crv":"P-256","x":"gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0","y":"e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck"}`,
+		`{"kty":"RSA","e":"AQAB","n":"not base64!"}`,
+		`{"kty":"oct","k":""}`,
+		`{"kid":"no kty"}`,
+	} {
+		if _, err := jwt.ParseJWKSet([]byte(`{"keys":[` + key + `]}`)); err == nil {
+			t.Errorf("invalid key should cause an error: %s", key)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package jwt
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
-	"crypto/elliptic"
+	"crypto/ed25519"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -22,6 +24,12 @@ type JWK struct {
 	Use        string            `json:"use,omitempty"`
 	Ext        bool              `json:"ext,omitempty"`
 	KeyOps     []string          `json:"key_ops,omitempty"`
+
+	// X.509 certificate chain (x5c), the first certificate containing the key
+	Certificates                []*x509.Certificate `json:"-"`
+	CertificateURL              string              `json:"x5u,omitempty"`
+	CertificateThumbprintSHA1   []byte              `json:"-"` // x5t
+	CertificateThumbprintSHA256 []byte              `json:"-"` // x5t#S256
 }
 
 func (jwk *JWK) String() string {
@@ -31,12 +39,18 @@ func (jwk *JWK) String() string {
 	return fmt.Sprintf("JWK[%T]", jwk.PublicKey)
 }
 
+// Public returns the public key of the JWK. Symmetric (oct) keys have no
+// public part, and nil is returned for them.
 func (jwk *JWK) Public() crypto.PublicKey {
 	if jwk.PrivateKey != nil {
 		if v, ok := jwk.PrivateKey.(interface{ Public() crypto.PublicKey }); ok {
 			return v.Public()
 		}
 	}
+	if _, ok := jwk.PublicKey.([]byte); ok {
+		// never expose a secret as a public key
+		return nil
+	}
 	return jwk.PublicKey
 }
 
@@ -50,6 +64,16 @@ func (jwk *JWK) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]b
 	return nil, ErrNoPrivateKey
 }
 
+func (jwk *JWK) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	if jwk.PrivateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+	if dec, ok := jwk.PrivateKey.(crypto.Decrypter); ok {
+		return dec.Decrypt(rand, msg, opts)
+	}
+	return nil, ErrNoPrivateKey
+}
+
 func (jwk *JWK) ThumbprintHex(method crypto.Hash) string {
 	v, err := jwk.Thumbprint(method)
 	if err != nil {
@@ -64,7 +88,15 @@ func (jwk *JWK) Thumbprint(method crypto.Hash) ([]byte, error) {
 	// https://www.rfc-editor.org/rfc/rfc7638
 
 	// we need to export in json format in alphabetical order. Golang does that for us :)
-	v, err := json.Marshal(jwk.ExportRequiredPublicValues())
+	pub := jwk.ExportRequiredPublicValues()
+	if k, ok := symmetricKey(jwk); ok {
+		// the secret is a required member of oct keys (RFC 7638, Section 3.2)
+		pub = jwkOctValues(k)
+	}
+	if pub == nil {
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidPublicKey, jwk.Public())
+	}
+	v, err := json.Marshal(pub)
 	if err != nil {
 		return nil, err
 	}
@@ -98,104 +130,97 @@ func (jwk *JWK) ApplyValues(values map[string]any) error {
 
 	switch kty {
 	case "RSA":
-		// e, n, and d if private key
-
-		// e=AQAB = 0x010001 = 65537
-		eB, err := jwkBase64ToBigInt(values["e"])
-		if err != nil {
-			return fmt.Errorf("while reading e: %w", err)
-		}
-		if !eB.IsUint64() {
-			return fmt.Errorf("value for e is too large")
-		}
-		e := eB.Uint64()
-		nB, err := jwkBase64ToBigInt(values["n"])
+		// n, e, and d if private key, with optional CRT values
+		pub, err := jwkRSAPublicKey(values)
 		if err != nil {
-			return fmt.Errorf("while reading n: %w", err)
+			return err
 		}
-
-		dA, ok := values["d"]
-		if ok {
-			// private key
-			dB, err := jwkBase64ToBigInt(dA)
+		if _, ok := values["d"]; ok {
+			res, err := jwkRSAPrivateKey(pub, values)
 			if err != nil {
-				return fmt.Errorf("while reading d: %w", err)
-			}
-			res := &rsa.PrivateKey{
-				PublicKey: rsa.PublicKey{
-					N: nB,
-					E: int(e),
-				},
-				D: dB,
-			}
-			if err = res.Validate(); err != nil {
-				return fmt.Errorf("invalid RSA private key: %w", err)
+				return err
 			}
 			jwk.PrivateKey = res
-			jwk.PublicKey = res.PublicKey
+			jwk.PublicKey = &res.PublicKey
 			break
 		}
 
 		// public only
-		jwk.PublicKey = &rsa.PublicKey{
-			N: nB,
-			E: int(e),
-		}
-		break
+		jwk.PublicKey = pub
 	case "EC":
-		var crv elliptic.Curve
-		switch values["crv"].(string) {
-		case "P-224":
-			crv = elliptic.P224()
-		case "P-256":
-			crv = elliptic.P256()
-		case "P-384":
-			crv = elliptic.P384()
-		case "P-521":
-			crv = elliptic.P521()
-		default:
-			return fmt.Errorf("unsupported curve %s", values["crv"])
+		priv, pub, err := jwkECKey(values)
+		if err != nil {
+			return err
 		}
-
-		// x, y and d in private key, only x and y if public
-		x, err := jwkBase64ToBigInt(values["x"])
+		if priv != nil {
+			jwk.PrivateKey = priv
+		}
+		jwk.PublicKey = pub
+	case "oct":
+		// symmetric key, k
+		k, err := jwkBase64ToBytes(values["k"])
 		if err != nil {
-			return fmt.Errorf("while reading x: %w", err)
+			return fmt.Errorf("while reading k: %w", err)
+		}
+		if len(k) == 0 {
+			return fmt.Errorf("oct key cannot be empty")
 		}
-		y, err := jwkBase64ToBigInt(values["y"])
+		jwk.PrivateKey = k
+	case "OKP":
+		// RFC 8037: crv, x, and d if private key
+		x, err := jwkBase64ToBytes(values["x"])
 		if err != nil {
-			return fmt.Errorf("while reading y: %w", err)
+			return fmt.Errorf("while reading x: %w", err)
 		}
-		dA, ok := values["d"]
-		if ok {
-			// private key
-			dB, err := jwkBase64ToBigInt(dA)
+		var d []byte
+		if dA, ok := values["d"]; ok {
+			d, err = jwkBase64ToBytes(dA)
 			if err != nil {
 				return fmt.Errorf("while reading d: %w", err)
 			}
-			res := &ecdsa.PrivateKey{
-				PublicKey: ecdsa.PublicKey{
-					Curve: crv,
-					X:     x,
-					Y:     y,
-				},
-				D: dB,
-			}
-			// TODO validate key?
-			jwk.PrivateKey = res
-			jwk.PublicKey = res.PublicKey
-			break
 		}
 
-		// public only
-		jwk.PublicKey = &ecdsa.PublicKey{
-			Curve: crv,
-			X:     x,
-			Y:     y,
+		switch values["crv"] {
+		case "Ed25519":
+			if len(x) != ed25519.PublicKeySize {
+				return fmt.Errorf("invalid Ed25519 public key length %d", len(x))
+			}
+			if d != nil {
+				if len(d) != ed25519.SeedSize {
+					return fmt.Errorf("invalid Ed25519 private key length %d", len(d))
+				}
+				res := ed25519.NewKeyFromSeed(d)
+				if !bytes.Equal(res[ed25519.SeedSize:], x) {
+					return fmt.Errorf("invalid Ed25519 private key: public key does not match")
+				}
+				jwk.PrivateKey = res
+				jwk.PublicKey = res.Public()
+				break
+			}
+			jwk.PublicKey = ed25519.PublicKey(x)
+		case "X25519":
+			pub, err := ecdh.X25519().NewPublicKey(x)
+			if err != nil {
+				return fmt.Errorf("invalid X25519 public key: %w", err)
+			}
+			if d != nil {
+				res, err := ecdh.X25519().NewPrivateKey(d)
+				if err != nil {
+					return fmt.Errorf("invalid X25519 private key: %w", err)
+				}
+				if !res.PublicKey().Equal(pub) {
+					return fmt.Errorf("invalid X25519 private key: public key does not match")
+				}
+				jwk.PrivateKey = res
+				jwk.PublicKey = res.PublicKey()
+				break
+			}
+			jwk.PublicKey = pub
+		default:
+			return fmt.Errorf("%w: OKP curve %v", ErrUnsupportedKey, values["crv"])
 		}
-		break
 	default:
-		return fmt.Errorf("unsupported value for kty=%s", kty)
+		return fmt.Errorf("%w: kty=%v", ErrUnsupportedKey, kty)
 	}
 
 	if kid, ok := values["kid"]; ok {
@@ -212,14 +237,54 @@ func (jwk *JWK) ApplyValues(values map[string]any) error {
 	if use, ok := values["use"].(string); ok {
 		jwk.Use = use
 	}
+	if ext, ok := values["ext"].(bool); ok {
+		jwk.Ext = ext
+	}
+	if ops := headerStrings(values["key_ops"]); len(ops) > 0 {
+		jwk.KeyOps = ops
+	}
 
-	return nil
+	return jwk.applyX509Values(values)
 }
 
 func (jwk *JWK) MarshalJSON() ([]byte, error) {
 	return json.Marshal(jwk.ExportValues())
 }
 
+// Encrypt returns the JWK, including its private key if any, encrypted as a
+// JWE as described in RFC 7517, Section 7. A PBES2 algo such as
+// PBES2HS512A256KW can be used to protect the key with a password, which is
+// then passed as key.
+func (jwk *JWK) Encrypt(rand io.Reader, alg KeyAlgo, enc EncAlgo, key crypto.PublicKey) (string, error) {
+	buf, err := json.Marshal(jwk.ExportValues())
+	if err != nil {
+		return "", err
+	}
+
+	tok := NewJWE(alg, enc)
+	tok.Header().Set("cty", "jwk+json")
+	return tok.Encrypt(rand, key, buf)
+}
+
+// DecryptJWK decrypts a JWK that was encrypted with JWK.Encrypt. See
+// JWE.Decrypt for the options.
+func DecryptJWK(value string, key crypto.PrivateKey, opts ...DecryptOption) (*JWK, error) {
+	tok, err := ParseJWE(value)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := tok.Decrypt(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &JWK{}
+	if err := res.UnmarshalJSON(buf); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (jwk *JWK) ExportValues() map[string]any {
 	res := jwk.ExportRequiredValues()
 
@@ -238,6 +303,18 @@ func (jwk *JWK) ExportValues() map[string]any {
 	if len(jwk.KeyOps) != 0 {
 		res["key_ops"] = jwk.KeyOps
 	}
+	if len(jwk.Certificates) != 0 {
+		res["x5c"] = x5cValues(jwk.Certificates)
+	}
+	if jwk.CertificateURL != "" {
+		res["x5u"] = jwk.CertificateURL
+	}
+	if len(jwk.CertificateThumbprintSHA1) != 0 {
+		res["x5t"] = base64.RawURLEncoding.EncodeToString(jwk.CertificateThumbprintSHA1)
+	}
+	if len(jwk.CertificateThumbprintSHA256) != 0 {
+		res["x5t#S256"] = base64.RawURLEncoding.EncodeToString(jwk.CertificateThumbprintSHA256)
+	}
 
 	return res
 }
@@ -246,30 +323,40 @@ func (jwk *JWK) ExportRequiredValues() map[string]any {
 	if jwk.PrivateKey != nil {
 		switch v := jwk.PrivateKey.(type) {
 		case *rsa.PrivateKey:
-			return map[string]any{
+			res := map[string]any{
 				"kty": "RSA",
 				"e":   jwkBigIntToBase64(big.NewInt(int64(v.E))),
 				"n":   jwkBigIntToBase64(v.N),
-				"d":   jwkBigIntToBase64(v.D),
 			}
+			jwkRSAPrivateValues(v, res)
+			return res
 		case *ecdsa.PrivateKey:
+			return jwkECValues(&v.PublicKey, v.D)
+		case []byte:
+			return jwkOctValues(v)
+		case ed25519.PrivateKey:
 			return map[string]any{
-				"kty": "EC",
-				"crv": v.Curve.Params().Name,
-				"d":   jwkBigIntToBase64(v.D),
-				"x":   jwkBigIntToBase64(v.PublicKey.X),
-				"y":   jwkBigIntToBase64(v.PublicKey.Y),
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"d":   base64.RawURLEncoding.EncodeToString(v.Seed()),
+				"x":   base64.RawURLEncoding.EncodeToString(v[ed25519.SeedSize:]),
+			}
+		case *ecdh.PrivateKey:
+			if v.Curve() == ecdh.X25519() {
+				return map[string]any{
+					"kty": "OKP",
+					"crv": "X25519",
+					"d":   base64.RawURLEncoding.EncodeToString(v.Bytes()),
+					"x":   base64.RawURLEncoding.EncodeToString(v.PublicKey().Bytes()),
+				}
 			}
 		}
 	}
-	if jwk.PublicKey != nil {
-		return jwk.ExportRequiredPublicValues()
-	}
-	return nil
+	return jwk.ExportRequiredPublicValues()
 }
 
 func (jwk *JWK) ExportRequiredPublicValues() map[string]any {
-	switch v := jwk.PublicKey.(type) {
+	switch v := jwk.Public().(type) {
 	case *rsa.PublicKey:
 		return map[string]any{
 			"kty": "RSA",
@@ -277,16 +364,33 @@ func (jwk *JWK) ExportRequiredPublicValues() map[string]any {
 			"n":   jwkBigIntToBase64(v.N),
 		}
 	case *ecdsa.PublicKey:
+		return jwkECValues(v, nil)
+	case ed25519.PublicKey:
 		return map[string]any{
-			"kty": "EC",
-			"crv": v.Curve.Params().Name,
-			"x":   jwkBigIntToBase64(v.X),
-			"y":   jwkBigIntToBase64(v.Y),
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(v),
+		}
+	case *ecdh.PublicKey:
+		if v.Curve() == ecdh.X25519() {
+			return map[string]any{
+				"kty": "OKP",
+				"crv": "X25519",
+				"x":   base64.RawURLEncoding.EncodeToString(v.Bytes()),
+			}
 		}
 	}
 	return nil
 }
 
+// jwkOctValues returns the values of a symmetric key, including its secret
+func jwkOctValues(k []byte) map[string]any {
+	return map[string]any{
+		"kty": "oct",
+		"k":   base64.RawURLEncoding.EncodeToString(k),
+	}
+}
+
 func jwkBase64ToBigInt(v any) (*big.Int, error) {
 	var vText string
 
@@ -313,6 +417,27 @@ func jwkBase64ToBigInt(v any) (*big.Int, error) {
 	return res, nil
 }
 
+// symmetricKey returns the secret of a symmetric key passed either as a
+// []byte or as a *JWK of type oct.
+func symmetricKey(key any) ([]byte, bool) {
+	if jwk, ok := key.(*JWK); ok {
+		key = jwk.PrivateKey
+	}
+	k, ok := key.([]byte)
+	return k, ok
+}
+
+func jwkBase64ToBytes(v any) ([]byte, error) {
+	switch xv := v.(type) {
+	case string:
+		return base64.RawURLEncoding.DecodeString(xv)
+	case []byte:
+		return base64.RawURLEncoding.DecodeString(string(xv))
+	default:
+		return nil, fmt.Errorf("unsupported base64 type %T", v)
+	}
+}
+
 func jwkBigIntToBase64(v *big.Int) string {
 	// so much easier!
 	return base64.RawURLEncoding.EncodeToString(v.Bytes())
@@ -0,0 +1,333 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Signature is one of the signatures of a token using the JWS JSON
+// serialization defined in RFC 7515, Section 7.2. Each signature has its own
+// protected and unprotected header.
+type Signature struct {
+	protected   string // encoded protected header
+	header      Header // decoded protected header
+	unprotected Header
+	sig         []byte
+}
+
+// jsonSignature is the JSON representation of a signature
+type jsonSignature struct {
+	Protected string `json:"protected,omitempty"`
+	Header    Header `json:"header,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// jsonToken is the JSON representation of a token, in either the flattened
+// or general syntax
+type jsonToken struct {
+	Payload *string `json:"payload,omitempty"` // nil if detached
+	jsonSignature
+	Signatures []*jsonSignature `json:"signatures,omitempty"`
+}
+
+// MultiSignatureMode defines how VerifySignatures handles tokens with more
+// than one signature.
+type MultiSignatureMode int
+
+const (
+	// AnySignature requires at least one signature to be valid
+	AnySignature MultiSignatureMode = iota
+	// AllSignatures requires all signatures to be valid, each for a different
+	// key
+	AllSignatures
+)
+
+// ParseJSON parses a token using the JWS JSON serialization, either in its
+// flattened or general syntax. The token's Header() will return the protected
+// header of the first signature, and Signatures() can be used to access all of
+// them.
+func ParseJSON(buf []byte) (*Token, error) {
+	var obj jsonToken
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	sigs := obj.Signatures
+	if obj.Signature != "" {
+		// flattened syntax
+		if len(sigs) != 0 {
+			return nil, fmt.Errorf("%w: both signature and signatures are set", ErrInvalidToken)
+		}
+		sigs = []*jsonSignature{&obj.jsonSignature}
+	}
+	if len(sigs) == 0 {
+		return nil, ErrNoSignature
+	}
+
+	tok := &Token{}
+	for _, js := range sigs {
+		sig, err := js.decode()
+		if err != nil {
+			return nil, err
+		}
+		tok.signatures = append(tok.signatures, sig)
+	}
+	if err := tok.checkB64(); err != nil {
+		return nil, err
+	}
+
+	var payload string
+	if obj.Payload != nil {
+		payload = *obj.Payload
+	} else {
+		tok.detached = true
+	}
+
+	// make the first signature available as if the token was compact
+	first := sigs[0]
+	tok.values = []string{first.Protected, payload, first.Signature}
+	tok.value = first.Protected + "." + payload + "." + first.Signature
+	tok.header = tok.signatures[0].Protected()
+	if tok.header == nil {
+		// the protected header is optional in the JSON serialization
+		tok.header = make(Header)
+	}
+
+	return tok, nil
+}
+
+func (js *jsonSignature) decode() (*Signature, error) {
+	sig := &Signature{protected: js.Protected, unprotected: js.Header}
+
+	var err error
+	sig.sig, err = base64.RawURLEncoding.DecodeString(js.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	if js.Protected != "" {
+		sig.header, err = decodeHeader(js.Protected)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+		}
+	}
+
+	// RFC 7515, Section 7.2.1: header parameter names must be disjoint
+	for k := range sig.unprotected {
+		if sig.header.Has(k) {
+			return nil, fmt.Errorf("%w: duplicate header parameter %s", ErrInvalidToken, k)
+		}
+	}
+	return sig, nil
+}
+
+// Protected returns the integrity protected header of the signature.
+func (s *Signature) Protected() Header {
+	return s.header
+}
+
+// Unprotected returns the unprotected header of the signature, which can be
+// nil.
+func (s *Signature) Unprotected() Header {
+	return s.unprotected
+}
+
+// Header returns the union of both protected and unprotected headers.
+func (s *Signature) Header() Header {
+	res := make(Header)
+	for k, v := range s.unprotected {
+		res[k] = v
+	}
+	for k, v := range s.header {
+		res[k] = v
+	}
+	return res
+}
+
+// isB64 returns false if the signature's protected header sets b64 to false,
+// as defined in RFC 7797.
+func (s *Signature) isB64() bool {
+	if v, ok := s.header.GetValue("b64").(bool); ok {
+		return v
+	}
+	return true
+}
+
+// GetAlgo returns the algorithm used for this signature.
+func (s *Signature) GetAlgo() (Algo, error) {
+	return s.Header().GetAlgo()
+}
+
+// GetKeyId returns the kid value from the signature's header.
+func (s *Signature) GetKeyId() string {
+	return s.Header().Get("kid")
+}
+
+// Verify checks the signature for the given token against a public key.
+func (s *Signature) Verify(tok *Token, pub crypto.PublicKey) error {
+	algo, err := s.GetAlgo()
+	if err != nil {
+		return err
+	}
+	if len(tok.values) < 2 {
+		return ErrNoPayload
+	}
+	if tok.detached {
+		return ErrDetachedPayload
+	}
+	buf := []byte(s.protected + "." + tok.values[1])
+	return algo.Verify(buf, s.sig, pub)
+}
+
+// setVerified records the signatures that were successfully verified, so that
+// header based verifications only consider those.
+func (tok *Token) setVerified(sigs ...*Signature) {
+	st := tok.state()
+	st.verified = append(st.verified, sigs...)
+}
+
+// policyHeaders returns the protected headers verifications such as
+// VerifyType should check: the ones of the signatures that have been
+// verified if any, or of all the signatures of the token otherwise.
+func (tok *Token) policyHeaders() []Header {
+	sigs := tok.Signatures()
+	if tok.vs != nil && len(tok.vs.verified) > 0 {
+		sigs = tok.vs.verified
+	}
+	if len(sigs) == 0 {
+		return []Header{tok.Header()}
+	}
+	res := make([]Header, 0, len(sigs))
+	for _, sig := range sigs {
+		res = append(res, sig.Protected())
+	}
+	return res
+}
+
+// Signatures returns the list of signatures of the token. For a token using
+// compact serialization, this will return a single signature.
+func (tok *Token) Signatures() []*Signature {
+	if tok.signatures != nil {
+		return tok.signatures
+	}
+	if len(tok.values) < 3 {
+		return nil
+	}
+	sig, err := tok.GetRawSignature()
+	if err != nil {
+		return nil
+	}
+	return []*Signature{{protected: tok.values[0], header: tok.Header(), sig: sig}}
+}
+
+// AddSignature signs the token's payload with priv and adds the signature to
+// the token, so it can be serialized using MarshalJSON. hdr is the protected
+// header to use for this signature and alg will be guessed from the key if
+// not set, and unprotected can be nil. The payload must not be modified once
+// a signature has been added.
+func (tok *Token) AddSignature(rand io.Reader, priv crypto.PrivateKey, hdr, unprotected Header) error {
+	if hdr == nil {
+		hdr = make(Header)
+	}
+	sig := &Signature{header: hdr, unprotected: unprotected}
+	algo, err := sig.GetAlgo()
+	if err != nil {
+		if !errors.Is(err, ErrAlgNotSet) {
+			return err
+		}
+		algo, err = GetAlgoForSigner(priv)
+		if err != nil {
+			return err
+		}
+		hdr.Set("alg", algo.String())
+	}
+
+	// RFC 7797, Section 3: all signatures must use the same b64 value
+	b64 := sig.isB64()
+	if len(tok.signatures) > 0 && tok.signatures[0].isB64() != b64 {
+		return fmt.Errorf("%w: all signatures must use the same b64 value", ErrInvalidToken)
+	}
+	if !b64 {
+		if crit := hdr.GetStrings("crit"); !stringsContain(crit, "b64") {
+			hdr.Set("crit", append(crit, "b64"))
+		}
+	}
+
+	payload, err := tok.encodePayloadAs(b64)
+	if err != nil {
+		return err
+	}
+	if len(tok.signatures) > 0 && tok.values[1] != payload {
+		return fmt.Errorf("%w: payload was modified after signing", ErrInvalidToken)
+	}
+
+	jsonVal, err := json.Marshal(hdr)
+	if err != nil {
+		return err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(jsonVal)
+
+	sig.protected = protected
+	sig.sig, err = algo.Sign(rand, []byte(protected+"."+payload), priv)
+	if err != nil {
+		return err
+	}
+
+	tok.signatures = append(tok.signatures, sig)
+
+	if len(tok.signatures) == 1 {
+		// first signature, make token available as compact
+		first := tok.signatures[0]
+		tok.values = []string{first.protected, payload, base64.RawURLEncoding.EncodeToString(first.sig)}
+		tok.value = tok.values[0] + "." + tok.values[1] + "." + tok.values[2]
+		tok.header = first.header
+	}
+	return nil
+}
+
+// MarshalJSON returns the token using the JWS JSON serialization. The
+// flattened syntax is used if the token has only one signature, and the
+// general syntax otherwise. See also MarshalGeneralJSON.
+func (tok *Token) MarshalJSON() ([]byte, error) {
+	sigs := tok.Signatures()
+	if len(sigs) == 1 {
+		obj := &jsonToken{Payload: tok.jsonPayload(), jsonSignature: *sigs[0].encode()}
+		return json.Marshal(obj)
+	}
+	return tok.MarshalGeneralJSON()
+}
+
+// MarshalGeneralJSON returns the token using the general syntax of the JWS
+// JSON serialization, even if the token only has one signature.
+func (tok *Token) MarshalGeneralJSON() ([]byte, error) {
+	sigs := tok.Signatures()
+	if len(sigs) == 0 {
+		return nil, ErrNoSignature
+	}
+	obj := &jsonToken{Payload: tok.jsonPayload()}
+	for _, sig := range sigs {
+		obj.Signatures = append(obj.Signatures, sig.encode())
+	}
+	return json.Marshal(obj)
+}
+
+// jsonPayload returns the payload value for JSON serialization, or nil if the
+// token's payload is detached
+func (tok *Token) jsonPayload() *string {
+	if tok.detached {
+		return nil
+	}
+	return &tok.values[1]
+}
+
+func (s *Signature) encode() *jsonSignature {
+	return &jsonSignature{
+		Protected: s.protected,
+		Header:    s.unprotected,
+		Signature: base64.RawURLEncoding.EncodeToString(s.sig),
+	}
+}
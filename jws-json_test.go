@@ -0,0 +1,242 @@
+package jwt_test
+
+import (
+	_ "crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestJWSJSON(t *testing.T) {
+	hkey := []byte("this is a hmac key")
+
+	tok := jwt.New()
+	tok.Payload().Set("iss", "myself")
+	if err := tok.AddSignature(zeroReader{}, Alice, jwt.Header{"kid": "alice"}, nil); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if err := tok.AddSignature(nil, hkey, jwt.Header{"alg": "HS256"}, jwt.Header{"kid": "hmac"}); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	buf, err := json.Marshal(tok)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+
+	tok2, err := jwt.ParseString(string(buf))
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if len(tok2.Signatures()) != 2 {
+		t.Fatalf("expected 2 signatures, got %d", len(tok2.Signatures()))
+	}
+	if tok2.Signatures()[1].GetKeyId() != "hmac" || tok2.Signatures()[1].Protected().Has("kid") {
+		t.Errorf("unprotected header was not parsed properly")
+	}
+	if tok2.Payload().GetString("iss") != "myself" {
+		t.Errorf("invalid value in body")
+	}
+
+	tests := []struct {
+		opt jwt.VerifyOption
+		ok  bool
+	}{
+		{jwt.VerifySignatures(jwt.AnySignature, Alice.Public()), true},
+		{jwt.VerifySignatures(jwt.AnySignature, hkey), true},
+		{jwt.VerifySignatures(jwt.AllSignatures, Alice.Public()), false},
+		{jwt.VerifySignatures(jwt.AllSignatures, Alice.Public(), hkey), true},
+		{jwt.VerifySignatures(jwt.AnySignature, Bob.Public()), false},
+		{jwt.VerifyAlgo(jwt.ES256), false},
+		{jwt.VerifyAlgo(jwt.ES256, jwt.HS256), true},
+	}
+	for n, test := range tests {
+		err := tok2.Verify(test.opt)
+		if test.ok && err != nil {
+			t.Errorf("test %d: unexpected failure: %s", n, err)
+		} else if !test.ok && err == nil {
+			t.Errorf("test %d: verification should have failed", n)
+		}
+	}
+
+	// the first signature is also usable as compact
+	if err := tok2.Verify(jwt.VerifySignature(Alice.Public())); err != nil {
+		t.Errorf("failed to verify first signature: %s", err)
+	}
+}
+
+func TestJWSJSONFlattened(t *testing.T) {
+	tok := jwt.New(jwt.HS256)
+	tok.Payload().Set("iss", "myself")
+	if _, err := tok.Sign(nil, []byte("key")); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	buf, err := json.Marshal(tok)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	var obj map[string]any
+	json.Unmarshal(buf, &obj)
+	if _, ok := obj["signature"]; !ok {
+		t.Errorf("expected flattened syntax, got %s", buf)
+	}
+
+	tok2, err := jwt.ParseJSON(buf)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifyAlgo(jwt.HS256), jwt.VerifySignature([]byte("key"))); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+}
+
+func TestJWSJSONUnencoded(t *testing.T) {
+	hkey := []byte("this is a hmac key")
+
+	tok := jwt.New()
+	tok.Payload().Set("iss", "myself")
+	if err := tok.AddSignature(nil, hkey, jwt.Header{"alg": "HS256", "b64": false}, nil); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if err := tok.AddSignature(nil, hkey, jwt.Header{"alg": "HS384"}, nil); err == nil {
+		t.Errorf("signatures with different b64 values should be rejected")
+	}
+	if v := tok.Signatures()[0].Protected().GetStrings("crit"); len(v) != 1 || v[0] != "b64" {
+		t.Errorf("b64 was not added to crit: %v", v)
+	}
+
+	buf, err := json.Marshal(tok)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	var obj map[string]any
+	json.Unmarshal(buf, &obj)
+	if obj["payload"] != `{"iss":"myself"}` {
+		t.Errorf("payload was not left unencoded: %v", obj["payload"])
+	}
+
+	tok2, err := jwt.ParseJSON(buf)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifySignatures(jwt.AllSignatures, hkey)); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+	if tok2.Payload().GetString("iss") != "myself" {
+		t.Errorf("invalid value in body")
+	}
+}
+
+func TestJWSJSONVerifiedHeader(t *testing.T) {
+	hkey := []byte("this is a hmac key")
+
+	tok := jwt.New()
+	tok.Payload().Set("iss", "myself")
+	if err := tok.AddSignature(zeroReader{}, Alice, jwt.Header{"typ": "JWT"}, nil); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if err := tok.AddSignature(nil, hkey, jwt.Header{"alg": "HS256"}, jwt.Header{"typ": "JWT"}); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	buf, err := json.Marshal(tok)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	tok2, err := jwt.ParseJSON(buf)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	tests := []struct {
+		opts []jwt.VerifyOption
+		ok   bool
+	}{
+		// typ is only protected in the first signature
+		{[]jwt.VerifyOption{jwt.VerifySignatures(jwt.AnySignature, Alice.Public()), jwt.VerifyType("JWT")}, true},
+		{[]jwt.VerifyOption{jwt.VerifySignatures(jwt.AnySignature, hkey), jwt.VerifyType("JWT")}, false},
+		{[]jwt.VerifyOption{jwt.VerifyType("JWT"), jwt.VerifySignatures(jwt.AnySignature, Alice.Public())}, false},
+	}
+	for n, test := range tests {
+		err := tok2.Verify(test.opts...)
+		if test.ok && err != nil {
+			t.Errorf("test %d: unexpected failure: %s", n, err)
+		} else if !test.ok && err == nil {
+			t.Errorf("test %d: verification should have failed", n)
+		}
+	}
+}
+
+func TestJWSJSONVerifySignature(t *testing.T) {
+	hkey := []byte("this is a hmac key")
+
+	tok := jwt.New()
+	tok.Payload().Set("iss", "myself")
+	if err := tok.AddSignature(zeroReader{}, Alice, nil, jwt.Header{"kid": "alice"}); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if err := tok.AddSignature(nil, hkey, jwt.Header{"alg": "HS256", "typ": "JWT"}, nil); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	buf, err := json.Marshal(tok)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	tok2, err := jwt.ParseJSON(buf)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	// only the protected header of the first signature is the token's header
+	if tok2.Header().Has("kid") || tok2.GetKeyId() != "" {
+		t.Errorf("unprotected kid should not be part of the token header")
+	}
+
+	// the second signature is valid for hkey
+	if err := tok2.Verify(jwt.VerifySignature(hkey), jwt.VerifyType("JWT")); err != nil {
+		t.Errorf("failed to verify second signature: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifySignature(Bob.Public())); err == nil {
+		t.Errorf("verification with an unknown key should fail")
+	}
+	v := &jwt.Verifier{Key: hkey, Algos: []jwt.Algo{jwt.ES256, jwt.HS256}}
+	if err := v.Verify(tok2); err != nil {
+		t.Errorf("failed to verify with Verifier: %s", err)
+	}
+}
+
+func TestJWSJSONDuplicateSignature(t *testing.T) {
+	tok := jwt.New()
+	tok.Payload().Set("iss", "myself")
+	if err := tok.AddSignature(zeroReader{}, Alice, nil, nil); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	buf, err := tok.MarshalGeneralJSON()
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+
+	// repeat the same signature
+	var obj map[string]any
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	sigs := obj["signatures"].([]any)
+	obj["signatures"] = append(sigs, sigs[0])
+	buf, _ = json.Marshal(obj)
+
+	tok2, err := jwt.ParseJSON(buf)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifySignatures(jwt.AnySignature, Alice.Public())); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifySignatures(jwt.AllSignatures, Alice.Public())); err == nil {
+		t.Errorf("repeated signature should not satisfy AllSignatures")
+	}
+	if err := tok2.Verify(jwt.VerifySignatures(jwt.AllSignatures, Alice.Public(), Bob.Public())); err == nil {
+		t.Errorf("repeated signature should not satisfy AllSignatures")
+	}
+}
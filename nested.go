@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"crypto"
+	"io"
+	"strings"
+)
+
+// Encrypt will wrap the signed token in the passed JWE, typically obtained
+// from NewJWE, with cty set to "JWT" as described in RFC 7519, Section 5.2,
+// producing a nested JWT. The token must have been signed first, see
+// SignAndEncrypt.
+func (tok *Token) Encrypt(rand io.Reader, jwe *JWE, key crypto.PublicKey) (string, error) {
+	if len(tok.values) < 3 || tok.value == "" {
+		return "", ErrNoSignature
+	}
+
+	if err := jwe.Header().Set("cty", "JWT"); err != nil {
+		return "", err
+	}
+	res, err := jwe.Encrypt(rand, key, []byte(tok.value))
+	if err != nil {
+		return "", err
+	}
+	tok.outer = jwe
+	return res, nil
+}
+
+// SignAndEncrypt will sign the token with priv, then encrypt it for pub,
+// returning a nested JWT.
+func (tok *Token) SignAndEncrypt(rand io.Reader, priv crypto.PrivateKey, jwe *JWE, pub crypto.PublicKey) (string, error) {
+	if _, err := tok.Sign(rand, priv); err != nil {
+		return "", err
+	}
+	return tok.Encrypt(rand, jwe, pub)
+}
+
+// Outer returns the JWE the token was decrypted from or encrypted into, or
+// nil if the token was not encrypted.
+func (tok *Token) Outer() *JWE {
+	return tok.outer
+}
+
+// IsNested returns true if the JWE's content type shows it contains a JWT.
+func (tok *JWE) IsNested() bool {
+	return strings.EqualFold(tok.GetContentType(), "application/jwt")
+}
+
+// DecryptToken will decrypt the JWE and parse the nested token it contains.
+// The token's signature is not verified, so Verify must be called on the
+// result. ErrNotNestedToken is returned if the JWE's cty value is not "JWT".
+// See Decrypt for the options.
+func (tok *JWE) DecryptToken(key crypto.PrivateKey, opts ...DecryptOption) (*Token, error) {
+	if !tok.IsNested() {
+		return nil, ErrNotNestedToken
+	}
+	buf, err := tok.Decrypt(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ParseString(string(buf))
+	if err != nil {
+		return nil, err
+	}
+	res.outer = tok
+	return res, nil
+}
+
+// ParseEncrypted parses a nested JWT and decrypts it using key. Tokens that
+// are not encrypted are rejected with ErrNotEncryptedToken, ParseString must
+// be used explicitly to accept those. As with ParseString, it is up to the
+// caller to call Verify, or DecryptAndVerify can be used instead.
+func ParseEncrypted(value string, key crypto.PrivateKey, opts ...DecryptOption) (*Token, error) {
+	if strings.Count(value, ".") != 4 {
+		return nil, ErrNotEncryptedToken
+	}
+	jwe, err := ParseJWE(value)
+	if err != nil {
+		return nil, err
+	}
+	return jwe.DecryptToken(key, opts...)
+}
+
+// DecryptAndVerify will parse the given value using ParseEncrypted with the
+// dec options, and run the passed verifications on the resulting token. The
+// value must be a nested JWT, signed tokens that were not encrypted are
+// rejected.
+//
+// Example use: DecryptAndVerify(value, priv, []DecryptOption{DecryptAlgo(RSAOAEP256)}, VerifyAlgo(ES256), VerifySignature(pub))
+func DecryptAndVerify(value string, key crypto.PrivateKey, dec []DecryptOption, opts ...VerifyOption) (*Token, error) {
+	tok, err := ParseEncrypted(value, key, dec...)
+	if err != nil {
+		return nil, err
+	}
+	if err := tok.Verify(opts...); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
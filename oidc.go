@@ -0,0 +1,231 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ProviderConfig is the OpenID Provider metadata as returned by the
+// /.well-known/openid-configuration endpoint, see OpenID Connect Discovery
+// 1.0, Section 3.
+type ProviderConfig struct {
+	Issuer                  string   `json:"issuer"`
+	AuthorizationEndpoint   string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint           string   `json:"token_endpoint,omitempty"`
+	UserInfoEndpoint        string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                 string   `json:"jwks_uri"`
+	ScopesSupported         []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported  []string `json:"response_types_supported,omitempty"`
+	SubjectTypesSupported   []string `json:"subject_types_supported,omitempty"`
+	IDTokenSigningAlgValues []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	ClaimsSupported         []string `json:"claims_supported,omitempty"`
+}
+
+// ParseProviderConfig parses an OpenID Provider configuration document.
+func ParseProviderConfig(buf []byte) (*ProviderConfig, error) {
+	res := &ProviderConfig{}
+	if err := json.Unmarshal(buf, res); err != nil {
+		return nil, err
+	}
+	if res.Issuer == "" || res.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: issuer and jwks_uri are required", ErrProviderDiscovery)
+	}
+	return res, nil
+}
+
+// DiscoverProvider fetches the configuration of the OpenID Provider identified
+// by issuer, and ensures the issuer value of the document matches it.
+func DiscoverProvider(ctx context.Context, client *http.Client, issuer string) (*ProviderConfig, error) {
+	u := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProviderDiscovery, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP status %s", ErrProviderDiscovery, resp.Status)
+	}
+	buf, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProviderDiscovery, err)
+	}
+
+	res, err := ParseProviderConfig(buf)
+	if err != nil {
+		return nil, err
+	}
+	if res.Issuer != issuer {
+		return nil, fmt.Errorf("%w: issuer %s does not match %s", ErrProviderDiscovery, res.Issuer, issuer)
+	}
+	return res, nil
+}
+
+// RemoteKeySet returns a RemoteKeySet for the provider's jwks_uri.
+func (p *ProviderConfig) RemoteKeySet(client *http.Client) *RemoteKeySet {
+	return NewRemoteKeySet(p.JWKSURI, client)
+}
+
+// IDTokenVerifier returns a Verifier for ID tokens issued by the provider to
+// the given client, as described in OpenID Connect Core 1.0, Section 3.1.3.7.
+// Signature keys are obtained with keys, typically the LookupKey method of the
+// provider's RemoteKeySet. Allowed algorithms are taken from the provider's
+// configuration, defaulting to RS256.
+//
+// Values specific to the authentication request can be checked by passing
+// VerifyNonce, VerifyAuthTime, VerifyAccessTokenHash or VerifyCodeHash to the
+// Verify methods.
+func (p *ProviderConfig) IDTokenVerifier(clientID string, keys KeyFunc) *Verifier {
+	var algos []Algo
+	for _, name := range p.IDTokenSigningAlgValues {
+		if a := parseAlgo(name); a != nil && a != None {
+			algos = append(algos, a)
+		}
+	}
+	if len(p.IDTokenSigningAlgValues) == 0 {
+		algos = []Algo{RS256}
+	}
+
+	return &Verifier{
+		Algos:          algos,
+		KeyFunc:        keys,
+		Issuer:         []string{p.Issuer},
+		Audience:       []string{clientID},
+		RequiredClaims: []string{"iss", "sub", "aud", "exp", "iat"},
+		Options:        []VerifyOption{VerifyAuthorizedParty(clientID)},
+	}
+}
+
+// VerifyAuthorizedParty returns a VerifyOption that will check the token's
+// azp claim matches clientID if present. The claim is required if the token
+// has multiple audiences.
+func VerifyAuthorizedParty(clientID string) VerifyOption {
+	return func(tok *Token) error {
+		azp, ok := tok.claimString("azp")
+		if !ok {
+			if aud, _ := tok.claimStrings("aud"); len(aud) > 1 {
+				return fmt.Errorf("%w: azp claim", ErrVerifyMissing)
+			}
+			return nil
+		}
+		if azp != clientID {
+			return fmt.Errorf("%w: unexpected authorized party %s", ErrVerifyFailed, azp)
+		}
+		return nil
+	}
+}
+
+// VerifyNonce returns a VerifyOption that will check the token's nonce claim
+// matches the value sent in the authentication request.
+func VerifyNonce(nonce string) VerifyOption {
+	return func(tok *Token) error {
+		v, ok := tok.claimString("nonce")
+		if !ok {
+			return fmt.Errorf("%w: nonce claim", ErrVerifyMissing)
+		}
+		if subtle.ConstantTimeCompare([]byte(v), []byte(nonce)) != 1 {
+			return fmt.Errorf("%w: nonce does not match", ErrVerifyFailed)
+		}
+		return nil
+	}
+}
+
+// VerifyAuthTime returns a VerifyOption that will check the token's auth_time
+// claim is present and no older than maxAge, as required when max_age was
+// passed in the authentication request. If now is zero, the time is taken
+// from the clock (see VerifyClock).
+func VerifyAuthTime(now time.Time, maxAge time.Duration) VerifyOption {
+	return func(tok *Token) error {
+		authTime, ok := tok.claimDate("auth_time")
+		if !ok {
+			return fmt.Errorf("%w: auth_time claim", ErrVerifyMissing)
+		}
+		if authTime.IsZero() {
+			return fmt.Errorf("%w: auth_time claim failed to parse", ErrVerifyFailed)
+		}
+		if tok.now(now).Sub(authTime) > maxAge+tok.leeway() {
+			return fmt.Errorf("%w: authentication is too old (auth_time claim)", ErrVerifyFailed)
+		}
+		return nil
+	}
+}
+
+// VerifyAccessTokenHash returns a VerifyOption that will check the token's
+// at_hash claim matches the access token issued with it. If req is false, the
+// check only happens if the claim is present.
+func VerifyAccessTokenHash(accessToken string, req bool) VerifyOption {
+	return verifyOIDCHash("at_hash", accessToken, req)
+}
+
+// VerifyCodeHash returns a VerifyOption that will check the token's c_hash
+// claim matches the authorization code issued with it. If req is false, the
+// check only happens if the claim is present.
+func VerifyCodeHash(code string, req bool) VerifyOption {
+	return verifyOIDCHash("c_hash", code, req)
+}
+
+func verifyOIDCHash(claim, value string, req bool) VerifyOption {
+	return func(tok *Token) error {
+		v, ok := tok.claimString(claim)
+		if !ok {
+			if req {
+				return fmt.Errorf("%w: %s claim", ErrVerifyMissing, claim)
+			}
+			return nil
+		}
+
+		algo, err := tok.GetAlgoErr()
+		if err != nil {
+			return err
+		}
+		expect, err := oidcHash(algo, value)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(v), []byte(expect)) != 1 {
+			return fmt.Errorf("%w: %s does not match", ErrVerifyFailed, claim)
+		}
+		return nil
+	}
+}
+
+// oidcHash returns the base64url encoding of the left-most half of the hash of
+// value, using the hash of the signature algo (SHA-512 for EdDSA), as used for
+// at_hash and c_hash.
+func oidcHash(algo Algo, value string) (string, error) {
+	var h crypto.Hash
+	if a, ok := algo.(interface{ Hash() crypto.Hash }); ok {
+		h = a.Hash()
+	}
+	if h == 0 && algo.String() == "EdDSA" {
+		h = crypto.SHA512
+	}
+	if h == 0 {
+		return "", fmt.Errorf("%w: no hash for algorithm %s", ErrVerifyFailed, algo)
+	}
+	if !h.Available() {
+		return "", fmt.Errorf("%w: %s", ErrHashNotAvailable, h.String())
+	}
+
+	hash := h.New()
+	hash.Write([]byte(value))
+	sum := hash.Sum(nil)
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]), nil
+}
@@ -0,0 +1,99 @@
+package jwt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestOIDCHash(t *testing.T) {
+	// values from OpenID Connect Core 1.0, Appendix A.4
+	priv := []byte("this is a hmac key")
+	tok := jwt.New(jwt.HS256)
+	tok.Payload().Set("at_hash", "77QmUPtjPfzWtF2AnpK9RQ")
+	tok.Payload().Set("c_hash", "LDktKdoQak3Pk0cnXxCltA")
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	err = tok2.Verify(
+		jwt.VerifyAccessTokenHash("jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y", true),
+		jwt.VerifyCodeHash("Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk", true),
+	)
+	if err != nil {
+		t.Errorf("failed to verify hashes: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifyAccessTokenHash("other", true)); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected at_hash mismatch, got %v", err)
+	}
+}
+
+func TestOIDCProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&jwt.ProviderConfig{
+			Issuer:                  srv.URL,
+			JWKSURI:                 srv.URL + "/jwks",
+			IDTokenSigningAlgValues: []string{"ES256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&jwt.JWKSet{Keys: []*jwt.JWK{{PublicKey: Alice.Public(), KeyID: "alice"}}})
+	})
+
+	ctx := context.Background()
+	cfg, err := jwt.DiscoverProvider(ctx, srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("failed to discover provider: %s", err)
+	}
+	if _, err := jwt.DiscoverProvider(ctx, srv.Client(), srv.URL+"/other"); err == nil {
+		t.Errorf("discovery with a different issuer should fail")
+	}
+
+	v := cfg.IDTokenVerifier("client1", cfg.RemoteKeySet(srv.Client()).LookupKey)
+
+	now := time.Now()
+	tok := jwt.New(jwt.ES256)
+	tok.Header().Set("kid", "alice")
+	tok.Payload().Set("iss", srv.URL)
+	tok.Payload().Set("sub", "user1")
+	tok.Payload().Set("aud", []string{"client1", "client2"})
+	tok.Payload().Set("azp", "client1")
+	tok.Payload().Set("exp", now.Add(time.Hour).Unix())
+	tok.Payload().Set("iat", now.Unix())
+	tok.Payload().Set("auth_time", now.Add(-time.Minute).Unix())
+	tok.Payload().Set("nonce", "n-0S6_WzA2Mj")
+	sign, err := tok.Sign(nil, Alice)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	if _, err := v.ParseAndVerifyContext(ctx, sign, jwt.VerifyNonce("n-0S6_WzA2Mj"), jwt.VerifyAuthTime(time.Time{}, time.Hour)); err != nil {
+		t.Errorf("failed to verify ID token: %s", err)
+	}
+	if _, err := v.ParseAndVerify(sign, jwt.VerifyNonce("other")); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected nonce mismatch, got %v", err)
+	}
+	if _, err := v.ParseAndVerify(sign, jwt.VerifyAuthTime(time.Time{}, time.Second)); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected auth_time failure, got %v", err)
+	}
+
+	v2 := cfg.IDTokenVerifier("client2", cfg.RemoteKeySet(srv.Client()).LookupKey)
+	if _, err := v2.ParseAndVerify(sign); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected azp failure, got %v", err)
+	}
+}
@@ -44,7 +44,12 @@ func (b Payload) Has(key string) bool {
 // or return an empty string if the value is not set or cannot be converted.
 // GetString will return an empty string in case of failure.
 func (b Payload) GetString(key string) string {
-	switch v := b.Get(key).(type) {
+	return anyToString(b.Get(key))
+}
+
+// anyToString converts a JSON value to a string, see Payload.GetString.
+func anyToString(val any) string {
+	switch v := val.(type) {
 	case string:
 		return v
 	case []byte:
@@ -52,7 +57,7 @@ func (b Payload) GetString(key string) string {
 	case float64:
 		return strconv.FormatFloat(v, 'g', -1, 64)
 	case int64:
-		return strconv.FormatInt(v, 64)
+		return strconv.FormatInt(v, 10)
 	case json.Number:
 		return v.String()
 	case nil:
@@ -62,9 +67,9 @@ func (b Payload) GetString(key string) string {
 		rv := reflect.ValueOf(v)
 		switch rv.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return strconv.FormatInt(rv.Int(), 64)
+			return strconv.FormatInt(rv.Int(), 10)
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			return strconv.FormatUint(rv.Uint(), 64)
+			return strconv.FormatUint(rv.Uint(), 10)
 		case reflect.Float32:
 			return strconv.FormatFloat(rv.Float(), 'g', -1, 32)
 		case reflect.Float64:
@@ -82,7 +87,12 @@ func (b Payload) GetString(key string) string {
 // If the value is a float or any other kind of number-y value, it will be
 // converted (truncated) and returned as an int, or 0 in case of failure.
 func (b Payload) GetInt(key string) int64 {
-	switch v := b.Get(key).(type) {
+	return anyToInt(b.Get(key))
+}
+
+// anyToInt converts a JSON value to an int64, see Payload.GetInt.
+func anyToInt(val any) int64 {
+	switch v := val.(type) {
 	case bool:
 		if v {
 			return 1
@@ -120,7 +130,12 @@ func (b Payload) GetInt(key string) int64 {
 // If the value is an int or any other kind of number-y value, it will be
 // converted to float64 and returned, or return 0 in case of failure.
 func (b Payload) GetFloat(key string) float64 {
-	switch v := b.Get(key).(type) {
+	return anyToFloat(b.Get(key))
+}
+
+// anyToFloat converts a JSON value to a float64, see Payload.GetFloat.
+func anyToFloat(val any) float64 {
+	switch v := val.(type) {
 	case string:
 		res, _ := strconv.ParseFloat(v, 64)
 		return res
@@ -155,5 +170,18 @@ func (b Payload) GetNumericDate(key string) time.Time {
 	if !b.Has(key) {
 		return time.Time{} // check IsZero() to see if invalid time was passed
 	}
+	switch v := b.Get(key).(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return time.Unix(i, 0)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}
+		}
+		return floatToTime(f)
+	case float64, float32:
+		return floatToTime(anyToFloat(v))
+	}
 	return time.Unix(b.GetInt(key), 0)
 }
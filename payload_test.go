@@ -0,0 +1,17 @@
+package jwt_test
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestGetStringIntegers(t *testing.T) {
+	// integer values used to be formatted with an invalid base and panic
+	p := jwt.Payload{"i": int64(-42), "u": uint8(7), "n": 1700000000}
+	for k, exp := range map[string]string{"i": "-42", "u": "7", "n": "1700000000"} {
+		if v := p.GetString(k); v != exp {
+			t.Errorf("bad GetString(%s) value: %s", k, v)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PBES2DefaultIterations is the PBKDF2 iteration count used when encrypting
+// if the header does not contain a p2c value.
+var PBES2DefaultIterations = 100000
+
+const (
+	// pbes2MinIterations is the minimum p2c value, as recommended by RFC
+	// 7518, Section 4.8.1.2.
+	pbes2MinIterations = 1000
+
+	// pbes2DefaultMaxIterations is the maximum p2c value accepted when
+	// decrypting, unless DecryptMaxIterations is used. It prevents a token
+	// from forcing an arbitrary amount of work to be done.
+	pbes2DefaultMaxIterations = 100000
+)
+
+// pbes2Algo implements PBES2 password based encryption as defined in RFC 7518,
+// Section 4.8. The value is the size of the AES key wrap key.
+type pbes2Algo int
+
+func (p pbes2Algo) String() string {
+	return fmt.Sprintf("PBES2-HS%d+A%dKW", p*16, p*8)
+}
+
+func (p pbes2Algo) Hash() crypto.Hash {
+	switch p {
+	case 16:
+		return crypto.SHA256
+	case 24:
+		return crypto.SHA384
+	case 32:
+		return crypto.SHA512
+	}
+	return crypto.Hash(0)
+}
+
+func (p pbes2Algo) WrapKey(rand io.Reader, h Header, size int, pub crypto.PublicKey) ([]byte, []byte, error) {
+	password, ok := pbes2Password(pub)
+	if !ok {
+		return nil, nil, ErrInvalidEncryptKey
+	}
+
+	if !h.Has("p2s") {
+		salt := make([]byte, 16)
+		if _, err := io.ReadFull(rand, salt); err != nil {
+			return nil, nil, err
+		}
+		h.Set("p2s", base64.RawURLEncoding.EncodeToString(salt))
+	}
+	if !h.Has("p2c") {
+		h.Set("p2c", PBES2DefaultIterations)
+	}
+
+	kek, err := p.deriveKey(h, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aesKwAlgo(p).WrapKey(rand, h, size, kek)
+}
+
+func (p pbes2Algo) UnwrapKey(h Header, encryptedKey []byte, size int, priv crypto.PrivateKey) ([]byte, error) {
+	return p.unwrapKey(h, encryptedKey, size, priv, pbes2DefaultMaxIterations)
+}
+
+// unwrapKey implements UnwrapKey, accepting p2c values up to maxIterations
+func (p pbes2Algo) unwrapKey(h Header, encryptedKey []byte, size int, priv crypto.PrivateKey, maxIterations int) ([]byte, error) {
+	password, ok := pbes2Password(priv)
+	if !ok {
+		return nil, ErrInvalidDecryptKey
+	}
+	if p2c := h.GetInt("p2c"); p2c > int64(maxIterations) {
+		return nil, fmt.Errorf("%w: p2c value %d is above the maximum of %d", ErrInvalidToken, p2c, maxIterations)
+	}
+
+	kek, err := p.deriveKey(h, password)
+	if err != nil {
+		return nil, err
+	}
+	return aesKwAlgo(p).UnwrapKey(h, encryptedKey, size, kek)
+}
+
+func (p pbes2Algo) deriveKey(h Header, password []byte) ([]byte, error) {
+	if !p.Hash().Available() {
+		return nil, fmt.Errorf("%w: %s", ErrHashNotAvailable, p.Hash().String())
+	}
+
+	p2s, err := base64.RawURLEncoding.DecodeString(h.Get("p2s"))
+	if err != nil {
+		return nil, fmt.Errorf("while reading p2s: %w", err)
+	}
+	if len(p2s) < 8 {
+		return nil, fmt.Errorf("%w: p2s must be at least 8 bytes", ErrInvalidToken)
+	}
+
+	p2c := h.GetInt("p2c")
+	if p2c < pbes2MinIterations {
+		return nil, fmt.Errorf("%w: p2c value %d is below the minimum of %d", ErrInvalidToken, p2c, pbes2MinIterations)
+	}
+
+	// salt is UTF8(alg) || 0x00 || p2s
+	salt := make([]byte, 0, len(p.String())+1+len(p2s))
+	salt = append(salt, p.String()...)
+	salt = append(salt, 0)
+	salt = append(salt, p2s...)
+
+	return pbkdf2.Key(password, salt, int(p2c), int(p), p.Hash().New), nil
+}
+
+func (p pbes2Algo) reg() KeyAlgo {
+	RegisterKeyAlgo(p)
+	return p
+}
+
+// pbes2Password returns the password passed as key, which can be either a
+// string, a []byte or a *JWK of type oct.
+func pbes2Password(key any) ([]byte, bool) {
+	if jwk, ok := key.(*JWK); ok {
+		key = jwk.PrivateKey
+	}
+	switch v := key.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	}
+	return nil, false
+}
@@ -0,0 +1,287 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteKeySet is a JWK Set fetched from a URL such as an identity provider's
+// jwks_uri. The set is cached according to the response's Cache-Control or
+// Expires headers, and fetched again when a token uses an unknown kid. If a
+// fetch fails, the last successfully fetched set is still used.
+//
+// It is safe for concurrent use, and its LookupKey method can be used as a
+// KeyFunc.
+type RemoteKeySet struct {
+	URL    string
+	Client *http.Client // http.DefaultClient if nil
+
+	// MinRefresh is the minimum delay between two fetches, used to limit
+	// fetches caused by unknown kid values. Defaults to one minute.
+	MinRefresh time.Duration
+
+	// DefaultTTL is used when the response has no caching headers. Defaults
+	// to one hour.
+	DefaultTTL time.Duration
+
+	// Timeout limits the duration of fetches. Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// Clock is used to compute cache expiration, or DefaultClock if nil.
+	Clock Clock
+
+	lk        sync.RWMutex
+	set       *JWKSet
+	err       error // error of the last fetch
+	expires   time.Time
+	lastFetch time.Time
+	fetching  chan struct{} // closed when the running fetch completes
+}
+
+// NewRemoteKeySet returns a RemoteKeySet for the given URL. The set is fetched
+// when first needed, or when Start is called.
+func NewRemoteKeySet(url string, client *http.Client) *RemoteKeySet {
+	return &RemoteKeySet{URL: url, Client: client}
+}
+
+// KeySet returns the current key set, fetching it if it has not been fetched
+// yet or has expired.
+func (r *RemoteKeySet) KeySet(ctx context.Context) (*JWKSet, error) {
+	r.lk.RLock()
+	set, expires := r.set, r.expires
+	r.lk.RUnlock()
+
+	if set != nil && r.now().Before(expires) {
+		return set, nil
+	}
+	return r.fetch(ctx, false)
+}
+
+// Refresh fetches the key set immediately, unless another fetch completed
+// less than MinRefresh ago.
+func (r *RemoteKeySet) Refresh(ctx context.Context) error {
+	_, err := r.fetch(ctx, true)
+	return err
+}
+
+// LookupKey is a KeyFunc returning the key matching the token from the remote
+// set, see JWKSet.LookupKey. If no key is found, the set is fetched again
+// (subject to MinRefresh) before failing.
+func (r *RemoteKeySet) LookupKey(ctx context.Context, tok *Token) (crypto.PublicKey, error) {
+	set, err := r.KeySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := set.LookupKey(ctx, tok)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return pub, err
+	}
+
+	// keys may have been rotated
+	newSet, ferr := r.fetch(ctx, true)
+	if ferr != nil || newSet == set {
+		return nil, err
+	}
+	return newSet.LookupKey(ctx, tok)
+}
+
+// Start refreshes the key set in the background whenever it expires, until ctx
+// is done.
+func (r *RemoteKeySet) Start(ctx context.Context) {
+	go func() {
+		for {
+			r.KeySet(ctx)
+
+			r.lk.RLock()
+			delay := r.expires.Sub(r.now())
+			r.lk.RUnlock()
+			if minDelay := r.minRefresh(); delay < minDelay {
+				delay = minDelay
+			}
+
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// fetch downloads the key set. If force is false, the set is only fetched if
+// it has expired, otherwise it is fetched unless the last fetch happened less
+// than MinRefresh ago. Failed fetches are also not retried before MinRefresh
+// has elapsed. On failure, the last good set is returned if any.
+//
+// Only one fetch runs at a time, in the background so that it is not
+// interrupted when the ctx of the call that started it is done. Calls wait
+// for it to complete or for their ctx to be done.
+func (r *RemoteKeySet) fetch(ctx context.Context, force bool) (*JWKSet, error) {
+	now := r.now()
+	r.lk.Lock()
+	set := r.set
+	if set != nil && !force && now.Before(r.expires) {
+		// another call may have fetched the set
+		r.lk.Unlock()
+		return set, nil
+	}
+	if !r.lastFetch.IsZero() && now.Sub(r.lastFetch) < r.minRefresh() {
+		err := r.err
+		r.lk.Unlock()
+		if set != nil {
+			return set, nil
+		}
+		return nil, err
+	}
+	wait := r.fetching
+	if wait == nil {
+		wait = make(chan struct{})
+		r.fetching = wait
+		go r.runFetch(wait)
+	}
+	r.lk.Unlock()
+
+	select {
+	case <-ctx.Done():
+		if set != nil {
+			return set, nil
+		}
+		return nil, ctx.Err()
+	case <-wait:
+	}
+
+	r.lk.RLock()
+	defer r.lk.RUnlock()
+	if r.set != nil {
+		return r.set, nil
+	}
+	return nil, r.err
+}
+
+// runFetch downloads the key set and updates r, then closes done.
+func (r *RemoteKeySet) runFetch(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+	defer cancel()
+
+	now := r.now()
+	newSet, ttl, err := r.download(ctx)
+
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	close(done)
+	r.fetching = nil
+	r.lastFetch = now
+	r.err = err
+	if err != nil {
+		if r.set != nil {
+			// keep using the last good set, and retry later
+			r.expires = now.Add(r.minRefresh())
+		}
+		return
+	}
+	r.set = newSet
+	r.expires = now.Add(ttl)
+}
+
+// download performs the HTTP request and returns the parsed set and how long
+// it can be cached.
+func (r *RemoteKeySet) download(ctx context.Context) (*JWKSet, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrKeySetFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("%w: HTTP status %s", ErrKeySetFetch, resp.Status)
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrKeySetFetch, err)
+	}
+	set, err := ParseJWKSet(buf)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrKeySetFetch, err)
+	}
+
+	ttl := r.cacheTTL(resp.Header)
+	if minTTL := r.minRefresh(); ttl < minTTL {
+		ttl = minTTL
+	}
+	return set, ttl, nil
+}
+
+// cacheTTL returns how long a response can be cached based on its headers
+func (r *RemoteKeySet) cacheTTL(h http.Header) time.Duration {
+	if cc := h.Get("Cache-Control"); cc != "" {
+		for _, d := range strings.Split(cc, ",") {
+			d = strings.ToLower(strings.TrimSpace(d))
+			switch {
+			case d == "no-cache" || d == "no-store":
+				return 0
+			case strings.HasPrefix(d, "max-age="):
+				if n, err := strconv.ParseInt(d[8:], 10, 64); err == nil && n >= 0 {
+					return time.Duration(n) * time.Second
+				}
+			}
+		}
+	}
+	if exp := h.Get("Expires"); exp != "" {
+		t, err := http.ParseTime(exp)
+		if err != nil {
+			// invalid values mean the response has already expired
+			return 0
+		}
+		now := r.now()
+		if date, err := http.ParseTime(h.Get("Date")); err == nil {
+			now = date
+		}
+		return t.Sub(now)
+	}
+	if r.DefaultTTL > 0 {
+		return r.DefaultTTL
+	}
+	return time.Hour
+}
+
+func (r *RemoteKeySet) now() time.Time {
+	if r.Clock != nil {
+		return r.Clock.Now()
+	}
+	return DefaultClock.Now()
+}
+
+func (r *RemoteKeySet) timeout() time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	return 30 * time.Second
+}
+
+func (r *RemoteKeySet) minRefresh() time.Duration {
+	if r.MinRefresh > 0 {
+		return r.MinRefresh
+	}
+	return time.Minute
+}
@@ -0,0 +1,226 @@
+package jwt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestRemoteKeySet(t *testing.T) {
+	var lk sync.Mutex
+	var requests int
+	fail := false
+	keys := &jwt.JWKSet{Keys: []*jwt.JWK{{PublicKey: Alice.Public(), KeyID: "alice"}}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lk.Lock()
+		defer lk.Unlock()
+		requests += 1
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(keys)
+	}))
+	defer srv.Close()
+
+	var clkLk sync.Mutex
+	now := time.Now()
+	advance := func(d time.Duration) {
+		clkLk.Lock()
+		defer clkLk.Unlock()
+		now = now.Add(d)
+	}
+
+	remote := jwt.NewRemoteKeySet(srv.URL, srv.Client())
+	remote.MinRefresh = time.Minute
+	remote.Clock = jwt.ClockFunc(func() time.Time {
+		clkLk.Lock()
+		defer clkLk.Unlock()
+		return now
+	})
+
+	sign := func(kid string, priv *jwt.JWK) string {
+		tok := jwt.New(jwt.ES256)
+		tok.Header().Set("kid", kid)
+		res, err := tok.Sign(nil, priv)
+		if err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		return res
+	}
+	verify := func(value string) error {
+		tok, err := jwt.ParseString(value)
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		return tok.Verify(jwt.VerifyAlgo(jwt.ES256), jwt.VerifySignatureWith(remote.LookupKey))
+	}
+	reqCount := func() int {
+		lk.Lock()
+		defer lk.Unlock()
+		return requests
+	}
+
+	aliceTok := sign("alice", Alice)
+	for i := 0; i < 3; i++ {
+		if err := verify(aliceTok); err != nil {
+			t.Errorf("failed to verify: %s", err)
+		}
+	}
+	if n := reqCount(); n != 1 {
+		t.Errorf("expected set to be cached, got %d requests", n)
+	}
+
+	// key rotation: bob's key is added, unknown kid triggers a fetch once
+	// MinRefresh has elapsed
+	bobTok := sign("bob", Bob)
+	lk.Lock()
+	keys = &jwt.JWKSet{Keys: []*jwt.JWK{{PublicKey: Bob.Public(), KeyID: "bob"}}}
+	lk.Unlock()
+	if err := verify(bobTok); !errors.Is(err, jwt.ErrKeyNotFound) {
+		t.Errorf("expected rate limited lookup to fail, got %v", err)
+	}
+	advance(61 * time.Second)
+	if err := verify(bobTok); err != nil {
+		t.Errorf("failed to verify after rotation: %s", err)
+	}
+	if n := reqCount(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+
+	// on failure the last good set is kept
+	lk.Lock()
+	fail = true
+	lk.Unlock()
+	advance(61 * time.Second)
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Errorf("refresh should not fail with a cached set: %s", err)
+	}
+	if err := verify(bobTok); err != nil {
+		t.Errorf("failed to verify with last good set: %s", err)
+	}
+	if n := reqCount(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+
+	// after a failure the set is fetched again once MinRefresh has elapsed,
+	// then cached according to max-age
+	lk.Lock()
+	fail = false
+	lk.Unlock()
+	for _, d := range []time.Duration{61 * time.Second, 30 * time.Minute} {
+		advance(d)
+		if err := verify(bobTok); err != nil {
+			t.Errorf("failed to verify: %s", err)
+		}
+		if n := reqCount(); n != 4 {
+			t.Errorf("expected 4 requests, got %d", n)
+		}
+	}
+	advance(time.Hour)
+	if err := verify(bobTok); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+	if n := reqCount(); n != 5 {
+		t.Errorf("expected expired set to be fetched, got %d requests", n)
+	}
+}
+
+func TestRemoteKeySetWait(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer srv.Close()
+
+	remote := jwt.NewRemoteKeySet(srv.URL, srv.Client())
+
+	// callers give up when their ctx is done, without interrupting the fetch
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		if _, err := remote.KeySet(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+		cancel()
+	}
+
+	close(release)
+	if _, err := remote.KeySet(context.Background()); err != nil {
+		t.Errorf("fetch failed: %s", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestRemoteKeySetError(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	remote := jwt.NewRemoteKeySet(srv.URL, srv.Client())
+	remote.Clock = jwt.ClockFunc(func() time.Time { return now })
+
+	// failed fetches are not retried before MinRefresh has elapsed
+	for i := 0; i < 3; i++ {
+		if _, err := remote.KeySet(context.Background()); !errors.Is(err, jwt.ErrKeySetFetch) {
+			t.Errorf("expected fetch error, got %v", err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+	now = now.Add(61 * time.Second)
+	if _, err := remote.KeySet(context.Background()); !errors.Is(err, jwt.ErrKeySetFetch) {
+		t.Errorf("expected fetch error, got %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestRemoteKeySetBackground(t *testing.T) {
+	requests := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requests <- struct{}{}:
+		default:
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer srv.Close()
+
+	remote := jwt.NewRemoteKeySet(srv.URL, srv.Client())
+	remote.MinRefresh = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remote.Start(ctx)
+
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-requests:
+		case <-timeout:
+			t.Fatalf("expected background refreshes, got %d requests", i)
+		}
+	}
+}
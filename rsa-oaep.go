@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+)
+
+type rsaOaepAlgo crypto.Hash
+
+func (h rsaOaepAlgo) String() string {
+	switch h.Hash() {
+	case crypto.SHA1:
+		return "RSA-OAEP"
+	case crypto.SHA256:
+		return "RSA-OAEP-256"
+	case crypto.SHA384:
+		return "RSA-OAEP-384"
+	case crypto.SHA512:
+		return "RSA-OAEP-512"
+	default:
+		return ""
+	}
+}
+
+func (h rsaOaepAlgo) Hash() crypto.Hash {
+	return crypto.Hash(h)
+}
+
+func (h rsaOaepAlgo) WrapKey(rand io.Reader, hdr Header, size int, pub crypto.PublicKey) ([]byte, []byte, error) {
+	if obj, ok := pub.(interface{ Public() crypto.PublicKey }); ok {
+		pub = obj.Public()
+	}
+	pk, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unknown type %T", ErrInvalidEncryptKey, pub)
+	}
+	if !h.Hash().Available() {
+		return nil, nil, fmt.Errorf("%w: %s", ErrHashNotAvailable, h.Hash().String())
+	}
+
+	cek := make([]byte, size)
+	if _, err := io.ReadFull(rand, cek); err != nil {
+		return nil, nil, err
+	}
+
+	encKey, err := rsa.EncryptOAEP(h.Hash().New(), rand, pk, cek, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, encKey, nil
+}
+
+func (h rsaOaepAlgo) UnwrapKey(hdr Header, encryptedKey []byte, size int, priv crypto.PrivateKey) ([]byte, error) {
+	pk, ok := priv.(crypto.Decrypter)
+	if !ok {
+		return nil, ErrInvalidDecryptKey
+	}
+	if !h.Hash().Available() {
+		return nil, fmt.Errorf("%w: %s", ErrHashNotAvailable, h.Hash().String())
+	}
+
+	cek, err := pk.Decrypt(nil, encryptedKey, &rsa.OAEPOptions{Hash: h.Hash()})
+	if err != nil || len(cek) != size {
+		// continue with a random key so that the failure is only detected
+		// when checking the authentication tag, as recommended by RFC 7516,
+		// Section 11.5, and does not act as an oracle
+		cek = make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+			return nil, err
+		}
+	}
+	return cek, nil
+}
+
+func (h rsaOaepAlgo) reg() KeyAlgo {
+	RegisterKeyAlgo(h)
+	return h
+}
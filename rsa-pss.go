@@ -28,6 +28,11 @@ func (h rsaPssAlgo) Hash() crypto.Hash {
 	return crypto.Hash(h)
 }
 
+// GenerateKey returns a new RSA key, see GenerateKey.
+func (h rsaPssAlgo) GenerateKey(rand io.Reader) (crypto.PrivateKey, error) {
+	return rsa.GenerateKey(rand, rsaKeySize(crypto.Hash(h)))
+}
+
 func (h rsaPssAlgo) Sign(rand io.Reader, buf []byte, priv crypto.PrivateKey) ([]byte, error) {
 	pk, ok := priv.(crypto.Signer)
 	if !ok {
@@ -0,0 +1,241 @@
+package jwt
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"sync"
+)
+
+// secp256k1Curve implements the secp256k1 curve (y² = x³ + 7) used by ES256K
+// (RFC 8812). The arithmetic in elliptic.CurveParams assumes a = -3 and cannot
+// be used for this curve, so points are computed here in Jacobian
+// coordinates. Operations are not constant time, and the curve is therefore
+// only used to verify signatures.
+type secp256k1Curve struct {
+	params *elliptic.CurveParams
+}
+
+var (
+	secp256k1Once sync.Once
+	secp256k1     *secp256k1Curve
+)
+
+// Secp256k1 returns an elliptic.Curve implementing secp256k1, which can be
+// used with crypto/ecdsa public keys to verify ES256K tokens. Like the curves
+// of crypto/elliptic, the returned value is a singleton.
+//
+// Its operations are not constant time and would leak private keys through
+// timing, so ES256K signature with keys on this curve and GenerateKey(ES256K)
+// are refused. Tokens can be signed with a crypto.Signer backed by a
+// constant-time secp256k1 implementation instead.
+func Secp256k1() elliptic.Curve {
+	secp256k1Once.Do(func() {
+		p := &elliptic.CurveParams{Name: "secp256k1", BitSize: 256}
+		p.P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+		p.N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+		p.B = big.NewInt(7)
+		p.Gx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+		p.Gy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+		secp256k1 = &secp256k1Curve{params: p}
+	})
+	return secp256k1
+}
+
+func (c *secp256k1Curve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+func (c *secp256k1Curve) IsOnCurve(x, y *big.Int) bool {
+	p := c.params.P
+	if x.Sign() < 0 || x.Cmp(p) >= 0 || y.Sign() < 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+
+	// y² = x³ + 7
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, p)
+
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+	x3.Add(x3, c.params.B)
+	x3.Mod(x3, p)
+
+	return x3.Cmp(y2) == 0
+}
+
+func (c *secp256k1Curve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	return c.toAffine(c.addJacobian(c.fromAffine(x1, y1), c.fromAffine(x2, y2)))
+}
+
+func (c *secp256k1Curve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	return c.toAffine(c.doubleJacobian(c.fromAffine(x1, y1)))
+}
+
+func (c *secp256k1Curve) ScalarMult(x1, y1 *big.Int, k []byte) (*big.Int, *big.Int) {
+	base := c.fromAffine(x1, y1)
+	res := secp256k1Point{new(big.Int), new(big.Int), new(big.Int)} // infinity
+
+	for _, b := range k {
+		for bit := 7; bit >= 0; bit-- {
+			res = c.doubleJacobian(res)
+			if (b>>uint(bit))&1 == 1 {
+				res = c.addJacobian(res, base)
+			}
+		}
+	}
+	return c.toAffine(res)
+}
+
+func (c *secp256k1Curve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.ScalarMult(c.params.Gx, c.params.Gy, k)
+}
+
+// secp256k1Point is a point in Jacobian coordinates, where (x, y, z)
+// represents the affine point (x/z², y/z³). z = 0 is the point at infinity.
+type secp256k1Point struct {
+	x, y, z *big.Int
+}
+
+func (c *secp256k1Curve) fromAffine(x, y *big.Int) secp256k1Point {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		// point at infinity
+		return secp256k1Point{new(big.Int), new(big.Int), new(big.Int)}
+	}
+	return secp256k1Point{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1)}
+}
+
+func (c *secp256k1Curve) toAffine(pt secp256k1Point) (*big.Int, *big.Int) {
+	if pt.z.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	p := c.params.P
+
+	zinv := new(big.Int).ModInverse(pt.z, p)
+	zinv2 := new(big.Int).Mul(zinv, zinv)
+	zinv2.Mod(zinv2, p)
+
+	x := new(big.Int).Mul(pt.x, zinv2)
+	x.Mod(x, p)
+	zinv2.Mul(zinv2, zinv)
+	y := new(big.Int).Mul(pt.y, zinv2)
+	y.Mod(y, p)
+	return x, y
+}
+
+// doubleJacobian uses the dbl-2009-l formulas for a = 0
+func (c *secp256k1Curve) doubleJacobian(pt secp256k1Point) secp256k1Point {
+	p := c.params.P
+	if pt.z.Sign() == 0 || pt.y.Sign() == 0 {
+		return secp256k1Point{new(big.Int), new(big.Int), new(big.Int)}
+	}
+
+	a := new(big.Int).Mul(pt.x, pt.x) // A = X1²
+	a.Mod(a, p)
+	b := new(big.Int).Mul(pt.y, pt.y) // B = Y1²
+	b.Mod(b, p)
+	cc := new(big.Int).Mul(b, b) // C = B²
+	cc.Mod(cc, p)
+
+	// D = 2*((X1+B)²-A-C)
+	d := new(big.Int).Add(pt.x, b)
+	d.Mul(d, d)
+	d.Sub(d, a)
+	d.Sub(d, cc)
+	d.Lsh(d, 1)
+	d.Mod(d, p)
+
+	e := new(big.Int).Mul(a, big.NewInt(3)) // E = 3*A
+	f := new(big.Int).Mul(e, e)             // F = E²
+
+	// X3 = F-2*D
+	x3 := new(big.Int).Sub(f, new(big.Int).Lsh(d, 1))
+	x3.Mod(x3, p)
+
+	// Y3 = E*(D-X3)-8*C
+	y3 := new(big.Int).Sub(d, x3)
+	y3.Mul(y3, e)
+	y3.Sub(y3, new(big.Int).Lsh(cc, 3))
+	y3.Mod(y3, p)
+
+	// Z3 = 2*Y1*Z1
+	z3 := new(big.Int).Mul(pt.y, pt.z)
+	z3.Lsh(z3, 1)
+	z3.Mod(z3, p)
+
+	return secp256k1Point{x3, y3, z3}
+}
+
+// addJacobian uses the add-2007-bl formulas
+func (c *secp256k1Curve) addJacobian(p1, p2 secp256k1Point) secp256k1Point {
+	if p1.z.Sign() == 0 {
+		return p2
+	}
+	if p2.z.Sign() == 0 {
+		return p1
+	}
+	p := c.params.P
+
+	z1z1 := new(big.Int).Mul(p1.z, p1.z)
+	z1z1.Mod(z1z1, p)
+	z2z2 := new(big.Int).Mul(p2.z, p2.z)
+	z2z2.Mod(z2z2, p)
+
+	u1 := new(big.Int).Mul(p1.x, z2z2)
+	u1.Mod(u1, p)
+	u2 := new(big.Int).Mul(p2.x, z1z1)
+	u2.Mod(u2, p)
+
+	s1 := new(big.Int).Mul(p1.y, p2.z)
+	s1.Mul(s1, z2z2)
+	s1.Mod(s1, p)
+	s2 := new(big.Int).Mul(p2.y, p1.z)
+	s2.Mul(s2, z1z1)
+	s2.Mod(s2, p)
+
+	h := new(big.Int).Sub(u2, u1)
+	h.Mod(h, p)
+	r := new(big.Int).Sub(s2, s1)
+	r.Mod(r, p)
+
+	if h.Sign() == 0 {
+		if r.Sign() == 0 {
+			// same point
+			return c.doubleJacobian(p1)
+		}
+		// opposite points
+		return secp256k1Point{new(big.Int), new(big.Int), new(big.Int)}
+	}
+	r.Lsh(r, 1) // r = 2*(S2-S1)
+
+	i := new(big.Int).Lsh(h, 1) // I = (2*H)²
+	i.Mul(i, i)
+	i.Mod(i, p)
+	j := new(big.Int).Mul(h, i) // J = H*I
+	j.Mod(j, p)
+	v := new(big.Int).Mul(u1, i) // V = U1*I
+	v.Mod(v, p)
+
+	// X3 = r²-J-2*V
+	x3 := new(big.Int).Mul(r, r)
+	x3.Sub(x3, j)
+	x3.Sub(x3, new(big.Int).Lsh(v, 1))
+	x3.Mod(x3, p)
+
+	// Y3 = r*(V-X3)-2*S1*J
+	y3 := new(big.Int).Sub(v, x3)
+	y3.Mul(y3, r)
+	s1.Mul(s1, j)
+	s1.Lsh(s1, 1)
+	y3.Sub(y3, s1)
+	y3.Mod(y3, p)
+
+	// Z3 = ((Z1+Z2)²-Z1Z1-Z2Z2)*H
+	z3 := new(big.Int).Add(p1.z, p2.z)
+	z3.Mul(z3, z3)
+	z3.Sub(z3, z1z1)
+	z3.Sub(z3, z2z2)
+	z3.Mul(z3, h)
+	z3.Mod(z3, p)
+
+	return secp256k1Point{x3, y3, z3}
+}
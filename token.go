@@ -2,10 +2,12 @@ package jwt
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 )
@@ -16,6 +18,13 @@ type Token struct {
 	payload Payload // parsed if needed
 	values  []string
 	value   string
+	claims  any          // typed claims, see TypedToken
+	outer   *JWE         // set for nested tokens
+	vs      *verifyState // set during Verify
+
+	detached bool // payload needs to be provided with SetDetachedPayload
+
+	signatures []*Signature // JSON serialization
 }
 
 // New will return a fresh and empty token that can be filled with information
@@ -29,20 +38,29 @@ func New(algopt ...Algo) *Token {
 
 	if alg == nil {
 		return &Token{
-			header:  make(map[string]string),
+			header:  make(Header),
 			payload: make(Payload),
 		}
 	}
 	return &Token{
-		header:  map[string]string{"alg": alg.String()},
+		header:  Header{"alg": alg.String()},
 		payload: make(Payload),
 	}
 }
 
 // ParseString will generate a Token object from an encoded string. No
 // verification is performed at this point, so it is up to you to call the
-// Verify method.
+// Verify method. Tokens using the JSON serialization are also accepted, see
+// ParseJSON.
 func ParseString(value string) (*Token, error) {
+	if strings.HasPrefix(value, "{") {
+		return ParseJSON([]byte(value))
+	}
+	if strings.Count(value, ".") == 4 {
+		// 5 parts, this is a JWE
+		return nil, ErrEncryptedToken
+	}
+
 	split := strings.SplitN(value, ".", 3)
 
 	if len(split) < 2 {
@@ -50,8 +68,9 @@ func ParseString(value string) (*Token, error) {
 	}
 
 	return &Token{
-		value:  value,
-		values: split,
+		value:    value,
+		values:   split,
+		detached: split[1] == "",
 	}, nil
 }
 
@@ -94,16 +113,7 @@ func (tok *Token) Header() Header {
 		return tok.header
 	}
 
-	str, err := base64.RawURLEncoding.DecodeString(tok.values[0])
-	if err != nil {
-		return nil
-	}
-
-	err = json.Unmarshal(str, &tok.header)
-	if err != nil {
-		return nil
-	}
-
+	tok.header, _ = decodeHeader(tok.values[0])
 	return tok.header
 }
 
@@ -115,7 +125,7 @@ func (tok *Token) Payload() Payload {
 		return tok.payload
 	}
 
-	str, err := base64.RawURLEncoding.DecodeString(tok.values[1])
+	str, err := tok.decodePayload()
 	if err != nil {
 		return nil
 	}
@@ -139,7 +149,12 @@ func (tok *Token) SetRawPayload(payload []byte, cty string) error {
 		tok.values = []string{"", ""}
 	}
 
-	tok.values[1] = base64.RawURLEncoding.EncodeToString(payload)
+	if tok.isB64() {
+		tok.values[1] = base64.RawURLEncoding.EncodeToString(payload)
+	} else {
+		tok.values[1] = string(payload)
+	}
+	tok.detached = false
 
 	if cty != "" {
 		return tok.Header().Set("cty", cty)
@@ -157,9 +172,126 @@ func (tok *Token) GetRawPayload() ([]byte, error) {
 		// no payload, return empty json object
 		return []byte("{}"), nil
 	}
+	return tok.decodePayload()
+}
+
+// decodePayload returns the raw payload from its value in the token
+func (tok *Token) decodePayload() ([]byte, error) {
+	if tok.detached {
+		return nil, ErrDetachedPayload
+	}
+	if !tok.isB64() {
+		return []byte(tok.values[1]), nil
+	}
 	return base64.RawURLEncoding.DecodeString(tok.values[1])
 }
 
+// isB64 returns false if the token uses an unencoded payload as defined in
+// RFC 7797. Only the protected header is taken into account.
+func (tok *Token) isB64() bool {
+	if len(tok.signatures) > 0 {
+		// all signatures have the same value, see checkB64
+		return tok.signatures[0].isB64()
+	}
+	if v, ok := tok.Header().GetValue("b64").(bool); ok {
+		return v
+	}
+	return true
+}
+
+// checkB64 ensures the b64 header parameter, if used, is integrity protected,
+// listed in crit and has the same value for all signatures, as required by
+// RFC 7797, Sections 3 and 6.
+func (tok *Token) checkB64() error {
+	if len(tok.signatures) == 0 {
+		return checkB64(tok.Header())
+	}
+	for _, sig := range tok.signatures {
+		if sig.unprotected.Has("b64") {
+			return fmt.Errorf("%w: b64 must be integrity protected", ErrInvalidToken)
+		}
+		if err := checkB64(sig.header); err != nil {
+			return err
+		}
+		if sig.isB64() != tok.signatures[0].isB64() {
+			return fmt.Errorf("%w: all signatures must use the same b64 value", ErrInvalidToken)
+		}
+	}
+	return nil
+}
+
+func checkB64(h Header) error {
+	v, ok := h["b64"]
+	if !ok {
+		return nil
+	}
+	if _, ok := v.(bool); !ok {
+		return fmt.Errorf("%w: b64 must be a boolean", ErrInvalidToken)
+	}
+	if !stringsContain(h.GetStrings("crit"), "b64") {
+		return fmt.Errorf("%w: b64 must be listed in crit", ErrInvalidToken)
+	}
+	return nil
+}
+
+// IsDetached returns true if the token was parsed without its payload, in
+// which case SetDetachedPayload must be called before it can be verified.
+func (tok *Token) IsDetached() bool {
+	return tok.detached
+}
+
+// SetDetachedPayload provides the payload of a token that was transmitted
+// separately, as described in RFC 7515, Appendix F. The payload is passed
+// as-is, without base64url encoding.
+func (tok *Token) SetDetachedPayload(payload []byte) error {
+	if len(tok.values) < 3 {
+		return ErrNoSignature
+	}
+	if tok.isB64() {
+		tok.values[1] = base64.RawURLEncoding.EncodeToString(payload)
+	} else {
+		tok.values[1] = string(payload)
+	}
+	tok.value = tok.values[0] + "." + tok.values[1] + "." + tok.values[2]
+	tok.payload = nil
+	tok.detached = false
+	return nil
+}
+
+// SetUnencodedPayload sets the "b64" header value to false and adds it to
+// "crit" as described in RFC 7797, so the payload will be signed as-is
+// without base64url encoding. As the payload will likely contain characters
+// not allowed in the compact serialization, this is typically used with
+// SignDetached.
+func (tok *Token) SetUnencodedPayload() error {
+	hdr := tok.Header()
+	if hdr == nil {
+		return ErrNoHeader
+	}
+	if !tok.isB64() {
+		return nil
+	}
+
+	if tok.payload == nil && len(tok.values) >= 2 && !tok.detached {
+		// convert existing payload
+		raw, err := base64.RawURLEncoding.DecodeString(tok.values[1])
+		if err != nil {
+			return err
+		}
+		tok.values[1] = string(raw)
+	}
+
+	hdr.Set("b64", false)
+	crit := hdr.GetStrings("crit")
+	for _, v := range crit {
+		if v == "b64" {
+			return nil
+		}
+	}
+	hdr.Set("crit", append(crit, "b64"))
+	return nil
+}
+
 // GetRawSignature returns the raw signature of a parsed token or of a freshly
 // signed token.
 func (tok *Token) GetRawSignature() ([]byte, error) {
@@ -171,7 +303,8 @@ func (tok *Token) GetRawSignature() ([]byte, error) {
 
 // GetSignString is used by VerifySignature to get the part of the string that
 // is used to generate a signature. It avoids duplicating memory in order to
-// provide better performance.
+// provide better performance. If the token uses an unencoded payload, the
+// payload is included as-is.
 func (tok Token) GetSignString() []byte {
 	ln := len(tok.values[0]) + len(tok.values[1]) + 1
 	return []byte(tok.value[:ln])
@@ -179,6 +312,17 @@ func (tok Token) GetSignString() []byte {
 
 // Sign will generate the token and sign it, making it ready for distribution.
 func (tok *Token) Sign(rand io.Reader, priv crypto.PrivateKey) (string, error) {
+	return tok.sign(rand, priv, false)
+}
+
+// SignDetached will sign the token similar to Sign, but the returned value
+// will not include the payload, which will need to be transmitted separately
+// (RFC 7515, Appendix F).
+func (tok *Token) SignDetached(rand io.Reader, priv crypto.PrivateKey) (string, error) {
+	return tok.sign(rand, priv, true)
+}
+
+func (tok *Token) sign(rand io.Reader, priv crypto.PrivateKey, detached bool) (string, error) {
 	algo, err := tok.GetAlgoErr()
 	if err != nil {
 		if !errors.Is(err, ErrAlgNotSet) {
@@ -203,18 +347,12 @@ func (tok *Token) Sign(rand io.Reader, priv crypto.PrivateKey) (string, error) {
 	}
 	values[0] = base64.RawURLEncoding.EncodeToString(jsonVal)
 
-	if tok.payload == nil {
-		if len(tok.values) < 2 {
-			values[1] = base64.RawURLEncoding.EncodeToString([]byte("{}")) // empty json payload
-		} else {
-			values[1] = tok.values[1] // copy existing value as maybe not JSON
-		}
-	} else {
-		jsonVal, err = json.Marshal(tok.Payload())
-		if err != nil {
-			return "", err
-		}
-		values[1] = base64.RawURLEncoding.EncodeToString(jsonVal)
+	values[1], err = tok.encodePayload()
+	if err != nil {
+		return "", err
+	}
+	if !detached && !tok.isB64() && strings.IndexByte(values[1], '.') != -1 {
+		return "", fmt.Errorf("%w: unencoded payload contains a period, use SignDetached", ErrInvalidToken)
 	}
 
 	// build buf
@@ -237,19 +375,78 @@ func (tok *Token) Sign(rand io.Reader, priv crypto.PrivateKey) (string, error) {
 
 	tok.value = buf.String()
 	tok.values = values
+	tok.signatures = nil
+	tok.detached = false
 
+	if detached {
+		if len(values) < 3 {
+			return values[0] + "..", nil
+		}
+		return values[0] + ".." + values[2], nil
+	}
 	return tok.value, nil
 }
 
+// encodePayload returns the base64url encoded value of the payload, as it is
+// used in the token, or the raw payload if b64 is false.
+func (tok *Token) encodePayload() (string, error) {
+	return tok.encodePayloadAs(tok.isB64())
+}
+
+// encodePayloadAs returns the value of the payload encoded with base64url if
+// b64 is true, or the raw payload otherwise.
+func (tok *Token) encodePayloadAs(b64 bool) (string, error) {
+	if tok.detached {
+		return "", ErrDetachedPayload
+	}
+
+	buf := []byte("{}") // empty json payload
+	if tok.payload == nil && len(tok.values) >= 2 {
+		if b64 == tok.isB64() {
+			return tok.values[1], nil // copy existing value as maybe not JSON
+		}
+		var err error
+		buf, err = tok.decodePayload()
+		if err != nil {
+			return "", err
+		}
+	} else if tok.payload != nil {
+		var err error
+		buf, err = json.Marshal(tok.payload)
+		if err != nil {
+			return "", err
+		}
+	}
+	if !b64 {
+		return string(buf), nil
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
 // Verify will perform the verifications passed as parameter in sequence,
 // stopping at the first failure. If all verifications are successful, nil will
-// be returned.
+// be returned. The token's crit header is always checked, see VerifyCritical.
 func (tok *Token) Verify(opts ...VerifyOption) error {
+	return tok.VerifyContext(context.Background(), opts...)
+}
+
+// VerifyContext performs the verifications similar to Verify, making ctx
+// available to options which may perform I/O, such as VerifySignatureWith.
+func (tok *Token) VerifyContext(ctx context.Context, opts ...VerifyOption) error {
+	tok.vs = &verifyState{ctx: ctx}
+
 	// check if we have header & payload
 	if tok.Header() == nil {
 		return ErrNoHeader
 	}
-	if tok.Payload() == nil {
+	if err := tok.checkB64(); err != nil {
+		return err
+	}
+	if tok.detached {
+		return ErrDetachedPayload
+	}
+	if tok.claims == nil && tok.Payload() == nil && tok.isB64() && strings.EqualFold(tok.GetContentType(), "application/jwt") {
+		// only JWT payloads are expected to be JSON
 		return ErrNoPayload
 	}
 
@@ -258,5 +455,7 @@ func (tok *Token) Verify(opts ...VerifyOption) error {
 			return err
 		}
 	}
-	return nil
+
+	// fail if the token has critical extensions we do not understand
+	return tok.checkCritical()
 }
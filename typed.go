@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"crypto"
+	"io"
+)
+
+// TypedToken is a Token which claims are decoded into a value of type T,
+// typically a struct embedding RegisteredClaims. Verification options such as
+// VerifyExpiresAt always check the claims of the signed payload, and ignore
+// modifications made to the value returned by Claims.
+type TypedToken[T any] struct {
+	*Token
+	claims *T
+}
+
+// NewTyped returns a new token using the passed claims, to be signed using
+// the Sign method. If claims is nil, a new zero value of T is used.
+func NewTyped[T any](alg Algo, claims *T) *TypedToken[T] {
+	if claims == nil {
+		claims = new(T)
+	}
+	tok := New(alg)
+	tok.claims = claims
+	return &TypedToken[T]{Token: tok, claims: claims}
+}
+
+// ParseTyped parses the token similar to ParseString, and decodes its payload
+// into a new value of type T. No verification is performed at this point.
+func ParseTyped[T any](value string) (*TypedToken[T], error) {
+	tok, err := ParseString(value)
+	if err != nil {
+		return nil, err
+	}
+	return NewTypedFromToken[T](tok)
+}
+
+// NewTypedFromToken decodes the claims of an existing token, for example one
+// returned by DecryptToken, into a TypedToken.
+func NewTypedFromToken[T any](tok *Token) (*TypedToken[T], error) {
+	claims := new(T)
+	if err := tok.Claims(claims); err != nil {
+		return nil, err
+	}
+	tok.claims = claims
+	return &TypedToken[T]{Token: tok, claims: claims}, nil
+}
+
+// Claims returns the typed claims of the token. Modifications will be taken
+// into account when the token is signed.
+func (tok *TypedToken[T]) Claims() *T {
+	return tok.claims
+}
+
+// Sign will encode the claims in the payload and sign the token.
+func (tok *TypedToken[T]) Sign(rand io.Reader, priv crypto.PrivateKey) (string, error) {
+	if err := tok.SetClaims(tok.claims); err != nil {
+		return "", err
+	}
+	return tok.Token.Sign(rand, priv)
+}
+
+// SignDetached will encode the claims in the payload and sign the token
+// without including the payload in the result, see Token.SignDetached.
+func (tok *TypedToken[T]) SignDetached(rand io.Reader, priv crypto.PrivateKey) (string, error) {
+	if err := tok.SetClaims(tok.claims); err != nil {
+		return "", err
+	}
+	return tok.Token.SignDetached(rand, priv)
+}
+
+// SignAndEncrypt will sign the token with its claims then encrypt it, see
+// Token.SignAndEncrypt.
+func (tok *TypedToken[T]) SignAndEncrypt(rand io.Reader, priv crypto.PrivateKey, jwe *JWE, pub crypto.PublicKey) (string, error) {
+	if _, err := tok.Sign(rand, priv); err != nil {
+		return "", err
+	}
+	return tok.Encrypt(rand, jwe, pub)
+}
@@ -0,0 +1,65 @@
+package jwt_test
+
+import (
+	_ "crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+type userClaims struct {
+	jwt.RegisteredClaims
+	UserId string `json:"uid"`
+}
+
+func TestTypedToken(t *testing.T) {
+	priv := []byte("this is a hmac key")
+	now := time.Now()
+
+	tok := jwt.NewTyped(jwt.HS256, &userClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "myself",
+			Audience:  jwt.ClaimStrings{"api"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		UserId: "42",
+	})
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseTyped[userClaims](sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := tok2.Claims()
+	if c.UserId != "42" || c.Issuer != "myself" || len(c.Audience) != 1 || c.Audience[0] != "api" {
+		t.Errorf("unexpected claims: %+v", c)
+	}
+	if tok2.Payload().GetString("aud") != "api" {
+		t.Errorf("aud with a single value should be encoded as a string")
+	}
+
+	err = tok2.Verify(jwt.VerifyAlgo(jwt.HS256), jwt.VerifySignature(priv), jwt.VerifyTime(now, true))
+	if err == nil {
+		t.Errorf("verification should fail as nbf is required but missing")
+	}
+	err = tok2.Verify(jwt.VerifyAlgo(jwt.HS256), jwt.VerifySignature(priv), jwt.VerifyExpiresAt(now, true))
+	if err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+
+	// verification checks the signed claims, not modified ones
+	c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour))
+	if err := tok2.Verify(jwt.VerifyAlgo(jwt.HS256), jwt.VerifySignature(priv), jwt.VerifyExpiresAt(now, true)); err != nil {
+		t.Errorf("modified claims should be ignored: %s", err)
+	}
+	later := now.Add(2 * time.Hour)
+	c.ExpiresAt = jwt.NewNumericDate(later.Add(time.Hour))
+	tok2.Payload().Set("exp", later.Add(time.Hour).Unix())
+	if err := tok2.Verify(jwt.VerifyAlgo(jwt.HS256), jwt.VerifySignature(priv), jwt.VerifyExpiresAt(later, true)); err == nil {
+		t.Errorf("verification of expired token should fail")
+	}
+}
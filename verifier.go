@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"time"
+)
+
+// Verifier holds a verification policy configured once and applied to any
+// number of tokens. Its fields must not be modified once it is in use, after
+// which it is safe for concurrent use.
+//
+// Example use:
+//
+//	v := &jwt.Verifier{
+//		Algos:    []jwt.Algo{jwt.ES256},
+//		Key:      publicKey,
+//		Issuer:   []string{"https://accounts.example.com"},
+//		Audience: []string{"my-client-id"},
+//		Leeway:   30 * time.Second,
+//	}
+//	tok, err := v.ParseAndVerify(input)
+type Verifier struct {
+	// Algos lists the accepted signature algorithms and must not be empty.
+	Algos []Algo
+
+	// Key is the public key used to verify the token's signature.
+	Key crypto.PublicKey
+
+	// KeyFunc is used to obtain the public key if Key is nil.
+	KeyFunc KeyFunc
+
+	// Issuer lists the accepted values for the iss claim, if not empty.
+	Issuer []string
+
+	// Audience lists the expected values for the aud claim, at least one of
+	// which must be present, if not empty.
+	Audience []string
+
+	// Types lists the accepted values for the typ header, if not empty.
+	Types []string
+
+	// RequiredClaims lists claims that must be present, such as "exp".
+	RequiredClaims []string
+
+	// MaxAge, if not zero, rejects tokens which iat claim is missing or older
+	// than the given duration.
+	MaxAge time.Duration
+
+	// Leeway is the allowed clock skew for exp, nbf and iat checks, or
+	// DefaultLeeway if zero.
+	Leeway time.Duration
+
+	// Clock is used for time-based checks, or DefaultClock if nil.
+	Clock Clock
+
+	// Options are additional verifications performed after the others.
+	Options []VerifyOption
+}
+
+// Verify verifies the token according to the policy of the Verifier. exp
+// and nbf are checked if present, and the token's crit header is checked as
+// with Token.Verify. opts are additional verifications specific to this call,
+// performed after the others.
+func (v *Verifier) Verify(tok *Token, opts ...VerifyOption) error {
+	return v.VerifyContext(context.Background(), tok, opts...)
+}
+
+// VerifyContext verifies the token similar to Verify, passing ctx to KeyFunc.
+func (v *Verifier) VerifyContext(ctx context.Context, tok *Token, extra ...VerifyOption) error {
+	if len(v.Algos) == 0 {
+		return fmt.Errorf("%w: no allowed algorithm", ErrVerifierConfig)
+	}
+
+	opts := []VerifyOption{VerifyAlgo(v.Algos...)}
+	switch {
+	case v.Key != nil:
+		opts = append(opts, VerifySignature(v.Key))
+	case v.KeyFunc != nil:
+		opts = append(opts, VerifySignatureWith(v.KeyFunc))
+	default:
+		return fmt.Errorf("%w: no key", ErrVerifierConfig)
+	}
+	if v.Leeway != 0 {
+		opts = append(opts, VerifyLeeway(v.Leeway))
+	}
+	if v.Clock != nil {
+		opts = append(opts, VerifyClock(v.Clock))
+	}
+	if len(v.Types) > 0 {
+		opts = append(opts, VerifyType(v.Types...))
+	}
+	if len(v.RequiredClaims) > 0 {
+		opts = append(opts, VerifyRequired(v.RequiredClaims...))
+	}
+	opts = append(opts,
+		VerifyTime(time.Time{}, false),
+		VerifyIssuedAt(time.Time{}, v.MaxAge, v.MaxAge > 0),
+	)
+	if len(v.Issuer) > 0 {
+		opts = append(opts, VerifyIssuer(v.Issuer...))
+	}
+	if len(v.Audience) > 0 {
+		opts = append(opts, VerifyAudience(v.Audience, false))
+	}
+	opts = append(opts, v.Options...)
+	opts = append(opts, extra...)
+
+	return tok.VerifyContext(ctx, opts...)
+}
+
+// ParseAndVerify parses the passed token using ParseString and verifies it,
+// returning the token only if the verification was successful.
+func (v *Verifier) ParseAndVerify(value string, opts ...VerifyOption) (*Token, error) {
+	return v.ParseAndVerifyContext(context.Background(), value, opts...)
+}
+
+// ParseAndVerifyContext is similar to ParseAndVerify, passing ctx to KeyFunc.
+func (v *Verifier) ParseAndVerifyContext(ctx context.Context, value string, opts ...VerifyOption) (*Token, error) {
+	tok, err := ParseString(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.VerifyContext(ctx, tok, opts...); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,80 @@
+package jwt_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestVerifier(t *testing.T) {
+	priv := []byte("this is a hmac key")
+	now := time.Unix(1700000000, 0)
+
+	tok := jwt.New(jwt.HS256)
+	tok.Header().Set("typ", "JWT")
+	tok.Payload().Set("iss", "me")
+	tok.Payload().Set("aud", "you")
+	tok.Payload().Set("iat", now.Unix())
+	tok.Payload().Set("exp", now.Add(time.Hour).Unix())
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	v := &jwt.Verifier{
+		Algos:          []jwt.Algo{jwt.HS256},
+		Key:            priv,
+		Issuer:         []string{"me"},
+		Audience:       []string{"you"},
+		Types:          []string{"jwt"},
+		RequiredClaims: []string{"exp"},
+		MaxAge:         time.Minute,
+		Leeway:         time.Second,
+		Clock:          jwt.ClockFunc(func() time.Time { return now.Add(30 * time.Second) }),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := v.ParseAndVerify(sign); err != nil {
+				t.Errorf("failed to verify: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	bad := *v
+	bad.Audience = []string{"someone else"}
+	if _, err := bad.ParseAndVerify(sign); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected audience verification failure, got %v", err)
+	}
+
+	bad = *v
+	bad.MaxAge = time.Second
+	if _, err := bad.ParseAndVerify(sign); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected max age verification failure, got %v", err)
+	}
+
+	bad = *v
+	bad.RequiredClaims = []string{"sub"}
+	if _, err := bad.ParseAndVerify(sign); !errors.Is(err, jwt.ErrVerifyMissing) {
+		t.Errorf("expected missing claim failure, got %v", err)
+	}
+
+	bad = *v
+	bad.Algos = nil
+	if _, err := bad.ParseAndVerify(sign); !errors.Is(err, jwt.ErrVerifierConfig) {
+		t.Errorf("expected configuration failure, got %v", err)
+	}
+
+	bad = *v
+	bad.Algos = []jwt.Algo{jwt.HS512}
+	if _, err := bad.ParseAndVerify(sign); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected algorithm failure, got %v", err)
+	}
+}
@@ -1,8 +1,10 @@
 package jwt
 
 import (
+	"context"
 	"crypto"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -13,65 +15,196 @@ type VerifyOption func(*Token) error
 // signature scheme and should always be used.
 func VerifyAlgo(algo ...Algo) VerifyOption {
 	return func(tok *Token) error {
+		if len(tok.signatures) > 0 {
+			// check each signature of the token
+			for _, sig := range tok.signatures {
+				sigAlgo, err := sig.GetAlgo()
+				if err != nil {
+					return err
+				}
+				if err := checkAlgo(sigAlgo, algo); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
 		tokAlgo, err := tok.GetAlgoErr()
 		if err != nil {
 			return err
 		}
+		return checkAlgo(tokAlgo, algo)
+	}
+}
 
-		// compare algo string in case we have two instances of the same object
-		name := tokAlgo.String()
+// checkAlgo ensures tokAlgo is one of the passed algos
+func checkAlgo(tokAlgo Algo, algo []Algo) error {
+	// compare algo string in case we have two instances of the same object
+	name := tokAlgo.String()
 
-		for _, a := range algo {
-			// do we need constant time compare here?
-			if name == a.String() {
-				return nil
-			}
+	for _, a := range algo {
+		// do we need constant time compare here?
+		if name == a.String() {
+			return nil
 		}
-
-		return fmt.Errorf("%w: unexpected signature algorithm %s", ErrVerifyFailed, tokAlgo)
 	}
+
+	return fmt.Errorf("%w: unexpected signature algorithm %s", ErrVerifyFailed, tokAlgo)
 }
 
 // VerifySignature will check the token's signature against the specified
 // public key based on the algo used for the token. This will always fail for
-// tokens which alg is set to "none".
+// tokens which alg is set to "none". For tokens with multiple signatures, such
+// as the ones parsed with ParseJSON, one of them must be valid. If pub is
+// PublicKeys, the signature must be valid for one of the keys.
 func VerifySignature(pub crypto.PublicKey) VerifyOption {
 	// pub is typically one of *rsa.PublicKey, *dsa.PublicKey, *ecdsa.PublicKey, or ed25519.PublicKey
 
 	return func(tok *Token) error {
-		sign, err := tok.GetRawSignature()
-		if err != nil {
-			return fmt.Errorf("jwt: failed to read signature: %w", err)
+		keys, ok := pub.(PublicKeys)
+		if !ok {
+			return tok.verifySignature(pub)
 		}
 
-		algo, err := tok.GetAlgoErr()
+		var err error = ErrInvalidPublicKey
+		for _, key := range keys {
+			if err = tok.verifySignature(key); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+}
+
+// verifySignature checks the token's signatures against pub, and records the
+// first valid one as verified
+func (tok *Token) verifySignature(pub crypto.PublicKey) error {
+	if tok.signatures == nil {
+		return tok.verifyCompact(pub)
+	}
+
+	var err error
+	for _, sig := range tok.signatures {
+		if err = sig.Verify(tok, pub); err == nil {
+			tok.setVerified(sig)
+			return nil
+		}
+	}
+	return err
+}
+
+// verifyCompact checks the signature of a token using the compact
+// serialization against pub
+func (tok *Token) verifyCompact(pub crypto.PublicKey) error {
+	sign, err := tok.GetRawSignature()
+	if err != nil {
+		return fmt.Errorf("jwt: failed to read signature: %w", err)
+	}
+
+	algo, err := tok.GetAlgoErr()
+	if err != nil {
+		return err
+	}
+
+	if err := algo.Verify(tok.GetSignString(), sign, pub); err != nil {
+		return err
+	}
+	if sigs := tok.Signatures(); len(sigs) > 0 {
+		tok.setVerified(sigs[0])
+	}
+	return nil
+}
+
+// KeyFunc returns the public key to be used to verify the token's signature,
+// typically based on the header's kid and alg values, or the payload's iss. It
+// may perform I/O, in which case ctx should be honored.
+type KeyFunc func(ctx context.Context, tok *Token) (crypto.PublicKey, error)
+
+// PublicKeys is a list of keys a KeyFunc can return when the key cannot be
+// determined from the token, for example if it has no kid. VerifySignature
+// will then accept a signature valid for any of the keys.
+type PublicKeys []crypto.PublicKey
+
+// VerifySignatureWith will check the token's signature similar to
+// VerifySignature, using the public key returned by fn. The context is the one
+// passed to Token.VerifyContext, or context.Background().
+//
+// Example use: tok.VerifyContext(ctx, VerifyAlgo(RS256), VerifySignatureWith(lookupKey))
+func VerifySignatureWith(fn KeyFunc) VerifyOption {
+	return func(tok *Token) error {
+		pub, err := fn(tok.context(), tok)
 		if err != nil {
-			return err
+			return fmt.Errorf("jwt: failed to obtain key: %w", err)
+		}
+		if pub == nil {
+			return ErrInvalidPublicKey
+		}
+		return VerifySignature(pub)(tok)
+	}
+}
+
+// VerifySignatures will check the signatures of a token, typically parsed
+// with ParseJSON, against the passed public keys. Depending on mode, either
+// one or all of the signatures must be valid for one of the keys. In the latter
+// case, each signature must be valid for a different key, so a signature
+// repeated in the token is not counted twice. For a token using the compact
+// serialization, this behaves like VerifySignature with multiple possible
+// keys.
+func VerifySignatures(mode MultiSignatureMode, pubs ...crypto.PublicKey) VerifyOption {
+	return func(tok *Token) error {
+		sigs := tok.Signatures()
+		if len(sigs) == 0 {
+			return ErrNoSignature
+		}
+
+		var valid []*Signature
+		used := make([]bool, len(pubs))
+		for _, sig := range sigs {
+			for n, pub := range pubs {
+				if mode == AllSignatures && used[n] {
+					// each signature must be from a different key
+					continue
+				}
+				if sig.Verify(tok, pub) == nil {
+					valid = append(valid, sig)
+					used[n] = true
+					break
+				}
+			}
 		}
 
-		return algo.Verify(tok.GetSignString(), sign, pub)
+		switch {
+		case len(valid) == 0:
+			return ErrInvalidSignature
+		case mode == AllSignatures && len(valid) != len(sigs):
+			return fmt.Errorf("%w: %d out of %d signatures are valid for distinct keys", ErrInvalidSignature, len(valid), len(sigs))
+		}
+		tok.setVerified(valid...)
+		return nil
 	}
 }
 
 // VerifyExpiresAt returns a VerifyOption that will check the token's
-// expiration to not be before now.
+// expiration to not be before now, minus the leeway set with VerifyLeeway or
+// DefaultLeeway. If now is zero, the time is taken from the clock (see
+// VerifyClock).
 //
 // Example use: VerifyExpiresAt(time.Now(), false)
 func VerifyExpiresAt(now time.Time, req bool) VerifyOption {
 	return func(t *Token) error {
-		if !t.Payload().Has("exp") {
+		exp, ok := t.claimDate("exp")
+		if !ok {
 			if req {
 				return fmt.Errorf("%w: ExpiresAt claim", ErrVerifyMissing)
 			}
 			return nil
 		}
-		exp := t.Payload().GetNumericDate("exp")
 		if exp.IsZero() {
 			return fmt.Errorf("%w: exp claim failed to parse", ErrVerifyFailed)
 		}
 
 		// exp date is before now, it means it's in the past
-		if exp.Before(now) {
+		if exp.Before(t.now(now).Add(-t.leeway())) {
 			return fmt.Errorf("%w: token has expired", ErrVerifyFailed)
 		}
 		return nil
@@ -79,23 +212,23 @@ func VerifyExpiresAt(now time.Time, req bool) VerifyOption {
 }
 
 // VerifyNotBefore returns a VerifyOption that will check the token's
-// not before claim (nbf).
+// not before claim (nbf), allowing for leeway similar to VerifyExpiresAt.
 //
 // Example use: VerifyNotBefore(time.Now(), false)
 func VerifyNotBefore(now time.Time, req bool) VerifyOption {
 	return func(tok *Token) error {
-		if !tok.Payload().Has("nbf") {
+		nbf, ok := tok.claimDate("nbf")
+		if !ok {
 			if req {
 				return fmt.Errorf("%w: NotBefore claim", ErrVerifyMissing)
 			}
 			return nil
 		}
-		nbf := tok.Payload().GetNumericDate("nbf")
 		if nbf.IsZero() {
 			return fmt.Errorf("%w: nbf claim failed to parse", ErrVerifyFailed)
 		}
 
-		if now.Before(nbf) {
+		if tok.now(now).Add(tok.leeway()).Before(nbf) {
 			return fmt.Errorf("%w: token is not valid yet (nbf claim)", ErrVerifyFailed)
 		}
 		return nil
@@ -112,6 +245,165 @@ func VerifyTime(now time.Time, req bool) VerifyOption {
 	return VerifyMultiple(VerifyExpiresAt(now, req), VerifyNotBefore(now, req))
 }
 
+// VerifyIssuer returns a VerifyOption that will check the token's issuer
+// claim (iss) is one of the allowed values. The claim is always required.
+//
+// Example use: VerifyIssuer("https://accounts.example.com")
+func VerifyIssuer(allowed ...string) VerifyOption {
+	return func(tok *Token) error {
+		iss, ok := tok.claimString("iss")
+		if !ok {
+			return fmt.Errorf("%w: Issuer claim", ErrVerifyMissing)
+		}
+		if !stringsContain(allowed, iss) {
+			return fmt.Errorf("%w: unexpected issuer %s", ErrVerifyFailed, iss)
+		}
+		return nil
+	}
+}
+
+// VerifyAudience returns a VerifyOption that will check the token's audience
+// claim (aud), which can be either a string or an array of strings. If
+// matchAll is false, the token must contain at least one of the expected
+// values, otherwise it must contain all of them.
+//
+// Example use: VerifyAudience([]string{"my-client-id"}, false)
+func VerifyAudience(expected []string, matchAll bool) VerifyOption {
+	return func(tok *Token) error {
+		aud, ok := tok.claimStrings("aud")
+		if !ok {
+			return fmt.Errorf("%w: Audience claim", ErrVerifyMissing)
+		}
+
+		found := 0
+		for _, e := range expected {
+			if stringsContain(aud, e) {
+				found += 1
+			}
+		}
+
+		switch {
+		case found == 0:
+			return fmt.Errorf("%w: token audience does not match", ErrVerifyFailed)
+		case matchAll && found != len(expected):
+			return fmt.Errorf("%w: token audience does not contain all expected values", ErrVerifyFailed)
+		}
+		return nil
+	}
+}
+
+// VerifySubject returns a VerifyOption that will check the token's subject
+// claim (sub) is set. If values are passed, the subject must be one of them.
+func VerifySubject(allowed ...string) VerifyOption {
+	return func(tok *Token) error {
+		sub, ok := tok.claimString("sub")
+		if !ok || sub == "" {
+			return fmt.Errorf("%w: Subject claim", ErrVerifyMissing)
+		}
+		if len(allowed) > 0 && !stringsContain(allowed, sub) {
+			return fmt.Errorf("%w: unexpected subject %s", ErrVerifyFailed, sub)
+		}
+		return nil
+	}
+}
+
+// VerifyJWTID returns a VerifyOption that will check the token's JWT ID claim
+// (jti) is set and pass it to check if not nil, typically to detect replays.
+// Any error returned by check will cause the verification to fail.
+func VerifyJWTID(check func(jti string) error) VerifyOption {
+	return func(tok *Token) error {
+		jti, ok := tok.claimString("jti")
+		if !ok || jti == "" {
+			return fmt.Errorf("%w: JWT ID claim", ErrVerifyMissing)
+		}
+		if check == nil {
+			return nil
+		}
+		if err := check(jti); err != nil {
+			return fmt.Errorf("%w: jti: %w", ErrVerifyFailed, err)
+		}
+		return nil
+	}
+}
+
+// VerifyIssuedAt returns a VerifyOption that will check the token's issued at
+// claim (iat) is not in the future and, if maxAge is not zero, that the token
+// was issued no longer than maxAge ago, allowing for leeway similar to
+// VerifyExpiresAt.
+//
+// Example use: VerifyIssuedAt(time.Now(), time.Hour, true)
+func VerifyIssuedAt(now time.Time, maxAge time.Duration, req bool) VerifyOption {
+	return func(tok *Token) error {
+		iat, ok := tok.claimDate("iat")
+		if !ok {
+			if req {
+				return fmt.Errorf("%w: IssuedAt claim", ErrVerifyMissing)
+			}
+			return nil
+		}
+		if iat.IsZero() {
+			return fmt.Errorf("%w: iat claim failed to parse", ErrVerifyFailed)
+		}
+
+		n := tok.now(now)
+		leeway := tok.leeway()
+		if n.Add(leeway).Before(iat) {
+			return fmt.Errorf("%w: token was issued in the future (iat claim)", ErrVerifyFailed)
+		}
+		if maxAge > 0 && n.Sub(iat) > maxAge+leeway {
+			return fmt.Errorf("%w: token is too old (iat claim)", ErrVerifyFailed)
+		}
+		return nil
+	}
+}
+
+// VerifyType returns a VerifyOption that will check the token's typ header is
+// one of the allowed values. Comparison is case insensitive and the
+// "application/" prefix is optional, as specified in RFC 7515, Section 4.1.9.
+//
+// For tokens with multiple signatures, the protected header of the signatures
+// that were verified by a previous option is checked, or of all signatures.
+//
+// Example use: VerifyType("JWT", "at+jwt")
+func VerifyType(allowed ...string) VerifyOption {
+	return func(tok *Token) error {
+		for _, hdr := range tok.policyHeaders() {
+			if err := checkType(hdr.Get("typ"), allowed); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// checkType ensures typ is one of the allowed values
+func checkType(typ string, allowed []string) error {
+	if typ == "" {
+		return fmt.Errorf("%w: typ header", ErrVerifyMissing)
+	}
+	typ = strings.TrimPrefix(strings.ToLower(typ), "application/")
+	for _, a := range allowed {
+		if typ == strings.TrimPrefix(strings.ToLower(a), "application/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unexpected token type %s", ErrVerifyFailed, typ)
+}
+
+// VerifyRequired returns a VerifyOption that will ensure all the passed
+// claims are present in the token's payload.
+func VerifyRequired(claims ...string) VerifyOption {
+	return func(tok *Token) error {
+		payload := tok.Payload()
+		for _, c := range claims {
+			if !payload.Has(c) {
+				return fmt.Errorf("%w: %s claim", ErrVerifyMissing, c)
+			}
+		}
+		return nil
+	}
+}
+
 // VerifyMultiple compounds multiple conditions and fails if any of the passed
 // condition fails. This will return success if no options are passed at all.
 func VerifyMultiple(opts ...VerifyOption) VerifyOption {
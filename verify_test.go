@@ -0,0 +1,268 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+func TestVerifyClaims(t *testing.T) {
+	priv := []byte("this is a hmac key")
+	now := time.Unix(1700000000, 0)
+
+	tok := jwt.New(jwt.HS256)
+	tok.Payload().Set("iss", "https://issuer.example.com")
+	tok.Payload().Set("sub", "user1")
+	tok.Payload().Set("aud", []string{"client1", "client2"})
+	tok.Payload().Set("jti", "id1")
+	tok.Payload().Set("iat", now.Add(-time.Minute).Unix())
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	errReplay := errors.New("replayed")
+
+	tests := []struct {
+		name string
+		opt  jwt.VerifyOption
+		err  error
+	}{
+		{"iss", jwt.VerifyIssuer("other", "https://issuer.example.com"), nil},
+		{"iss bad", jwt.VerifyIssuer("other"), jwt.ErrVerifyFailed},
+		{"sub", jwt.VerifySubject(), nil},
+		{"sub bad", jwt.VerifySubject("user2"), jwt.ErrVerifyFailed},
+		{"aud any", jwt.VerifyAudience([]string{"client2", "client3"}, false), nil},
+		{"aud all", jwt.VerifyAudience([]string{"client2", "client3"}, true), jwt.ErrVerifyFailed},
+		{"aud none", jwt.VerifyAudience([]string{"client3"}, false), jwt.ErrVerifyFailed},
+		{"jti", jwt.VerifyJWTID(func(string) error { return nil }), nil},
+		{"jti replay", jwt.VerifyJWTID(func(string) error { return errReplay }), errReplay},
+		{"iat", jwt.VerifyIssuedAt(now, time.Hour, true), nil},
+		{"iat future", jwt.VerifyIssuedAt(now.Add(-time.Hour), 0, true), jwt.ErrVerifyFailed},
+		{"iat too old", jwt.VerifyIssuedAt(now, time.Second, true), jwt.ErrVerifyFailed},
+	}
+
+	for _, test := range tests {
+		err := tok2.Verify(jwt.VerifySignature(priv), test.opt)
+		if test.err == nil && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		} else if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %s, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestVerifyClaimsString(t *testing.T) {
+	priv := []byte("this is a hmac key")
+
+	tok := jwt.New(jwt.HS256)
+	tok.Payload().Set("aud", "client1")
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifySignature(priv), jwt.VerifyAudience([]string{"client1"}, true)); err != nil {
+		t.Errorf("failed to verify string aud: %s", err)
+	}
+	for _, opt := range []jwt.VerifyOption{jwt.VerifyIssuer("x"), jwt.VerifySubject(), jwt.VerifyJWTID(nil), jwt.VerifyIssuedAt(time.Now(), 0, true)} {
+		if err := tok2.Verify(opt); !errors.Is(err, jwt.ErrVerifyMissing) {
+			t.Errorf("expected missing claim error, got %v", err)
+		}
+	}
+}
+
+func TestVerifyTypedClaims(t *testing.T) {
+	priv := []byte("this is a hmac key")
+
+	tok := jwt.NewTyped[jwt.RegisteredClaims](jwt.HS256, &jwt.RegisteredClaims{
+		Issuer:   "me",
+		Audience: jwt.ClaimStrings{"you"},
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	})
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseTyped[jwt.RegisteredClaims](sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	err = tok2.Verify(jwt.VerifySignature(priv), jwt.VerifyIssuer("me"), jwt.VerifyAudience([]string{"you"}, true), jwt.VerifyIssuedAt(time.Now().Add(time.Second), time.Minute, true))
+	if err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+}
+
+func TestVerifyLeeway(t *testing.T) {
+	priv := []byte("this is a hmac key")
+	now := time.Unix(1700000000, 0)
+	clk := jwt.ClockFunc(func() time.Time { return now })
+
+	tok := jwt.New(jwt.HS256)
+	tok.Payload().Set("exp", 1699999998.5) // fractional values are allowed
+	tok.Payload().Set("nbf", now.Add(3*time.Second).Unix())
+	tok.Payload().Set("iat", now.Add(3*time.Second).Unix())
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if v := tok2.Payload().GetNumericDate("exp"); !v.Equal(time.Unix(1699999998, 5e8)) {
+		t.Errorf("bad exp value: %s", v)
+	}
+
+	for _, opt := range []jwt.VerifyOption{jwt.VerifyExpiresAt(time.Time{}, true), jwt.VerifyNotBefore(time.Time{}, true), jwt.VerifyIssuedAt(time.Time{}, 0, true)} {
+		if err := tok2.Verify(jwt.VerifyClock(clk), opt); !errors.Is(err, jwt.ErrVerifyFailed) {
+			t.Errorf("expected verification to fail without leeway, got %v", err)
+		}
+		if err := tok2.Verify(jwt.VerifyClock(clk), jwt.VerifyLeeway(5*time.Second), opt); err != nil {
+			t.Errorf("failed to verify with leeway: %s", err)
+		}
+	}
+
+	// DefaultLeeway applies when none is set
+	jwt.DefaultLeeway = 5 * time.Second
+	defer func() { jwt.DefaultLeeway = 0 }()
+	if err := tok2.Verify(jwt.VerifyClock(clk), jwt.VerifyTime(time.Time{}, true)); err != nil {
+		t.Errorf("failed to verify with default leeway: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifyLeeway(time.Second), jwt.VerifyTime(now, true)); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected verification to fail with short leeway, got %v", err)
+	}
+}
+
+func TestVerifyIssuedAtReuse(t *testing.T) {
+	// the same option must use the current time on each call
+	now := time.Unix(1700000000, 0)
+	clk := jwt.ClockFunc(func() time.Time { return now })
+	opt := jwt.VerifyIssuedAt(time.Time{}, time.Hour, true)
+
+	for i := 0; i < 2; i++ {
+		tok := jwt.New(jwt.HS256)
+		tok.Payload().Set("iat", now.Unix())
+		sign, err := tok.Sign(nil, []byte("this is a hmac key"))
+		if err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		tok2, err := jwt.ParseString(sign)
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		if err := tok2.Verify(jwt.VerifyClock(clk), opt); err != nil {
+			t.Errorf("failed to verify token %d: %s", i, err)
+		}
+		now = now.Add(2 * time.Hour)
+	}
+}
+
+func TestVerifySignatureWith(t *testing.T) {
+	keys := map[string][]byte{
+		"key1": []byte("this is a hmac key"),
+		"key2": []byte("this is another hmac key"),
+	}
+	lookup := func(ctx context.Context, tok *jwt.Token) (crypto.PublicKey, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if k, ok := keys[tok.GetKeyId()]; ok {
+			return k, nil
+		}
+		return nil, errors.New("key not found")
+	}
+
+	tok := jwt.New(jwt.HS256)
+	tok.Header().Set("kid", "key2")
+	sign, err := tok.Sign(nil, keys["key2"])
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if err := tok2.Verify(jwt.VerifyAlgo(jwt.HS256), jwt.VerifySignatureWith(lookup)); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := tok2.VerifyContext(ctx, jwt.VerifySignatureWith(lookup)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled context error, got %v", err)
+	}
+
+	v := &jwt.Verifier{Algos: []jwt.Algo{jwt.HS256}, KeyFunc: lookup}
+	if _, err := v.ParseAndVerifyContext(context.Background(), sign); err != nil {
+		t.Errorf("failed to verify with verifier: %s", err)
+	}
+
+	tok.Header().Set("kid", "key3")
+	sign, err = tok.Sign(nil, keys["key2"])
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if _, err := v.ParseAndVerify(sign); err == nil {
+		t.Errorf("verification with unknown kid should fail")
+	}
+}
+
+func TestTypedCustomClaims(t *testing.T) {
+	type claims struct {
+		jwt.RegisteredClaims
+		Nonce    string           `json:"nonce"`
+		Groups   []string         `json:"groups"`
+		AuthTime *jwt.NumericDate `json:"auth_time"`
+	}
+
+	priv := []byte("this is a hmac key")
+	tok := jwt.NewTyped(jwt.HS256, &claims{
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "me"},
+		Nonce:            "n1",
+		Groups:           []string{"admin"},
+		AuthTime:         jwt.NewNumericDate(time.Unix(1700000000, 0)),
+	})
+	sign, err := tok.Sign(nil, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	tok2, err := jwt.ParseTyped[claims](sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	// claims that are not part of RegisteredClaims are read from the payload
+	if v, ok := tok2.ClaimString("nonce"); !ok || v != "n1" {
+		t.Errorf("bad nonce value: %s %v", v, ok)
+	}
+	if v, ok := tok2.ClaimStrings("groups"); !ok || len(v) != 1 || v[0] != "admin" {
+		t.Errorf("bad groups value: %v %v", v, ok)
+	}
+	if v, ok := tok2.ClaimDate("auth_time"); !ok || v.Unix() != 1700000000 {
+		t.Errorf("bad auth_time value: %s %v", v, ok)
+	}
+	if v, ok := tok2.ClaimString("iss"); !ok || v != "me" {
+		t.Errorf("bad iss value: %s %v", v, ok)
+	}
+	if _, ok := tok2.ClaimString("sub"); ok {
+		t.Errorf("unset sub claim should not be found")
+	}
+}
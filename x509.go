@@ -0,0 +1,195 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// parseX5C parses a x5c value, which is an array of base64 (not base64url)
+// encoded DER certificates, as described in RFC 7515, Section 4.1.6.
+func parseX5C(v any) ([]*x509.Certificate, error) {
+	list := headerStrings(v)
+	if _, isStr := v.(string); isStr || len(list) == 0 {
+		return nil, fmt.Errorf("x5c must be a non-empty array")
+	}
+
+	res := make([]*x509.Certificate, 0, len(list))
+	for n, s := range list {
+		der, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("while reading x5c[%d]: %w", n, err)
+		}
+		crt, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("while reading x5c[%d]: %w", n, err)
+		}
+		res = append(res, crt)
+	}
+	return res, nil
+}
+
+// x5cValues returns the x5c value for the given certificates
+func x5cValues(certs []*x509.Certificate) []string {
+	res := make([]string, 0, len(certs))
+	for _, crt := range certs {
+		res = append(res, base64.StdEncoding.EncodeToString(crt.Raw))
+	}
+	return res
+}
+
+// checkX5T ensures the x5t and x5t#S256 values of h, if any, match crt.
+func checkX5T(h map[string]any, crt *x509.Certificate) error {
+	if v, ok := h["x5t"].(string); ok {
+		sum := sha1.Sum(crt.Raw)
+		if v != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return fmt.Errorf("x5t does not match certificate")
+		}
+	}
+	if v, ok := h["x5t#S256"].(string); ok {
+		sum := sha256.Sum256(crt.Raw)
+		if v != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return fmt.Errorf("x5t#S256 does not match certificate")
+		}
+	}
+	return nil
+}
+
+// applyX509Values reads the x5c, x5u, x5t and x5t#S256 values of a JWK,
+// ensuring the first certificate matches the key (RFC 7517, Section 4.7).
+func (jwk *JWK) applyX509Values(values map[string]any) error {
+	if u, ok := values["x5u"].(string); ok {
+		jwk.CertificateURL = u
+	}
+	for k, ptr := range map[string]*[]byte{"x5t": &jwk.CertificateThumbprintSHA1, "x5t#S256": &jwk.CertificateThumbprintSHA256} {
+		if v, ok := values[k]; ok {
+			buf, err := jwkBase64ToBytes(v)
+			if err != nil {
+				return fmt.Errorf("while reading %s: %w", k, err)
+			}
+			*ptr = buf
+		}
+	}
+
+	v, ok := values["x5c"]
+	if !ok {
+		return nil
+	}
+	certs, err := parseX5C(v)
+	if err != nil {
+		return err
+	}
+	pub, ok := jwk.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(certs[0].PublicKey) {
+		return fmt.Errorf("x5c certificate does not match key")
+	}
+	if err := checkX5T(values, certs[0]); err != nil {
+		return err
+	}
+	jwk.Certificates = certs
+	return nil
+}
+
+// VerifyX5C returns a VerifyOption that will verify the token's signature
+// using the key of the certificate found in its x5c header, after validating
+// the certificate chain against roots. The certificates must be valid at the
+// current time (see VerifyClock) and allow one of the given extended key
+// usages. At least one usage must be passed, x509.ExtKeyUsageAny can be used
+// to accept any usage.
+//
+// If pinSHA256 is not nil, the SHA-256 thumbprint of the signing certificate
+// (x5t#S256) must match it.
+//
+// Only x5c values found in protected headers are used. For tokens with
+// multiple signatures, at least one of them must be verified this way.
+//
+// Any CA in roots can issue a certificate passing this verification, so the
+// identity of the signer should be checked once the token is verified, see
+// Token.VerifiedChain.
+//
+// Example use: VerifyX5C(roots, nil, x509.ExtKeyUsageCodeSigning)
+func VerifyX5C(roots *x509.CertPool, pinSHA256 []byte, usages ...x509.ExtKeyUsage) VerifyOption {
+	return func(tok *Token) error {
+		if len(usages) == 0 {
+			return fmt.Errorf("%w: no extended key usage for x5c", ErrVerifierConfig)
+		}
+		sigs := tok.Signatures()
+		if len(sigs) == 0 {
+			return ErrNoSignature
+		}
+
+		err := fmt.Errorf("%w: x5c header", ErrVerifyMissing)
+		for _, sig := range sigs {
+			v, ok := sig.Protected()["x5c"]
+			if !ok {
+				continue
+			}
+			if err = verifyX5C(tok, sig, v, roots, pinSHA256, usages); err == nil {
+				tok.setVerified(sig)
+				return nil
+			}
+		}
+		return err
+	}
+}
+
+// verifyX5C validates the certificate chain x5c of sig, and verifies the
+// signature using the key of its first certificate.
+func verifyX5C(tok *Token, sig *Signature, x5c any, roots *x509.CertPool, pinSHA256 []byte, usages []x509.ExtKeyUsage) error {
+	certs, err := parseX5C(x5c)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	leaf := certs[0]
+	if err := checkX5T(sig.Protected(), leaf); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if pinSHA256 != nil {
+		sum := sha256.Sum256(leaf.Raw)
+		if subtle.ConstantTimeCompare(sum[:], pinSHA256) != 1 {
+			return fmt.Errorf("%w: certificate does not match pinned thumbprint", ErrVerifyFailed)
+		}
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   tok.now(time.Time{}),
+		KeyUsages:     usages,
+	}
+	for _, crt := range certs[1:] {
+		opts.Intermediates.AddCert(crt)
+	}
+	chains, err := leaf.Verify(opts)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVerifyFailed, err)
+	}
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return fmt.Errorf("%w: certificate cannot be used for signatures", ErrVerifyFailed)
+	}
+
+	if err := sig.Verify(tok, leaf.PublicKey); err != nil {
+		return err
+	}
+	tok.state().chain = chains[0]
+	return nil
+}
+
+// VerifiedChain returns the certificate chain validated by VerifyX5C during
+// the last call to Token.Verify, starting with the signing certificate and
+// ending with a certificate from roots. It returns nil if no chain was
+// validated, and should only be used if Token.Verify succeeded.
+//
+// Example use: tok.VerifiedChain()[0].Subject.CommonName
+func (tok *Token) VerifiedChain() []*x509.Certificate {
+	if tok.vs == nil {
+		return nil
+	}
+	return tok.vs.chain
+}
@@ -0,0 +1,141 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/jwt"
+)
+
+// testCertificate returns a new certificate signed by parent, or self-signed
+// if parent is nil.
+func testCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if parent != nil {
+		tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+	}
+	if parent == nil {
+		tpl.IsCA = true
+		tpl.BasicConstraintsValid = true
+		tpl.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = tpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	return crt, key
+}
+
+func TestVerifyX5C(t *testing.T) {
+	ca, caKey := testCertificate(t, "Test CA", nil, nil)
+	leaf, leafKey := testCertificate(t, "Test signer", ca, caKey)
+	other, _ := testCertificate(t, "Other CA", nil, nil)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(other)
+
+	key := &jwt.JWK{PrivateKey: leafKey, Certificates: []*x509.Certificate{leaf, ca}}
+	tok := jwt.New(jwt.ES256)
+	tok.Header().Set("x5c", key.ExportValues()["x5c"])
+	sign, err := tok.Sign(nil, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	tok2, err := jwt.ParseString(sign)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	pin := sha256.Sum256(leaf.Raw)
+	if err := tok2.Verify(jwt.VerifyAlgo(jwt.ES256), jwt.VerifyX5C(roots, pin[:], x509.ExtKeyUsageCodeSigning)); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+	if chain := tok2.VerifiedChain(); len(chain) != 2 || !chain[0].Equal(leaf) || !chain[1].Equal(ca) {
+		t.Errorf("bad verified chain: %v", chain)
+	}
+	if err := tok2.Verify(jwt.VerifyX5C(roots, nil)); !errors.Is(err, jwt.ErrVerifierConfig) {
+		t.Errorf("expected missing key usage error, got %v", err)
+	}
+	if tok2.VerifiedChain() != nil {
+		t.Errorf("verified chain should be reset by Verify")
+	}
+	if err := tok2.Verify(jwt.VerifyX5C(roots, nil, x509.ExtKeyUsageServerAuth)); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected key usage failure, got %v", err)
+	}
+	if err := tok2.Verify(jwt.VerifyX5C(otherRoots, nil, x509.ExtKeyUsageAny)); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected chain verification failure, got %v", err)
+	}
+	if err := tok2.Verify(jwt.VerifyX5C(roots, make([]byte, 32), x509.ExtKeyUsageAny)); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected pin failure, got %v", err)
+	}
+	future := jwt.ClockFunc(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	if err := tok2.Verify(jwt.VerifyClock(future), jwt.VerifyX5C(roots, nil, x509.ExtKeyUsageAny)); !errors.Is(err, jwt.ErrVerifyFailed) {
+		t.Errorf("expected expired certificate failure, got %v", err)
+	}
+
+	// with multiple signatures, only x5c values in protected headers are used
+	tok3, tok4 := jwt.New(), jwt.New()
+	for _, tk := range []*jwt.Token{tok3, tok4} {
+		tk.Payload().Set("iss", "myself")
+		if err := tk.AddSignature(nil, []byte("hmac key"), jwt.Header{"alg": "HS256"}, nil); err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+	}
+	if err := tok3.AddSignature(rand.Reader, key, jwt.Header{"alg": "ES256", "x5c": key.ExportValues()["x5c"]}, nil); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if err := tok3.Verify(jwt.VerifyX5C(roots, nil, x509.ExtKeyUsageCodeSigning)); err != nil {
+		t.Errorf("failed to verify second signature: %s", err)
+	}
+	if err := tok4.AddSignature(rand.Reader, key, jwt.Header{"alg": "ES256"}, jwt.Header{"x5c": key.ExportValues()["x5c"]}); err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if err := tok4.Verify(jwt.VerifyX5C(roots, nil, x509.ExtKeyUsageCodeSigning)); !errors.Is(err, jwt.ErrVerifyMissing) {
+		t.Errorf("expected missing x5c error, got %v", err)
+	}
+
+	// JWK round trip with certificates
+	key.CertificateThumbprintSHA256 = pin[:]
+	buf, err := json.Marshal(key)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	key2 := parseJwk(buf)
+	if len(key2.Certificates) != 2 || !key2.Certificates[1].Equal(ca) || string(key2.CertificateThumbprintSHA256) != string(pin[:]) {
+		t.Errorf("certificates were not imported")
+	}
+
+	// the certificate must match the key
+	values := key.ExportValues()
+	values["x5c"] = []any{base64.StdEncoding.EncodeToString(ca.Raw)}
+	if err := (&jwt.JWK{}).ApplyValues(values); err == nil {
+		t.Errorf("certificate not matching the key should be rejected")
+	}
+}